@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shashidhxr/queueCTL/internal/store"
+	"github.com/shashidhxr/queueCTL/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cancelState string
+	cancelQueue string
+	cancelAll   bool
+)
+
+var cancelCmd = &cobra.Command{
+	Use:   "cancel [job-id]",
+	Short: "Cancel a job, or every pending/running job matching --state and --queue",
+	Long: `Pending jobs are cancelled immediately. Running jobs are flagged, and the
+worker holding the lease stops them on its next heartbeat (SIGTERM to the job's
+process group, then SIGKILL after the worker's --kill-grace).`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		if len(args) == 1 {
+			prev, err := st.CancelJob(ctx, args[0])
+			if err != nil { return err }
+			if prev == models.StateProcessing {
+				fmt.Printf("Cancel requested: %s (running; its worker will stop it)\n", args[0])
+			} else {
+				fmt.Printf("Cancelled: %s\n", args[0])
+			}
+			return nil
+		}
+
+		state := models.JobState(cancelState)
+		switch state {
+		case "", models.StatePending, models.StateProcessing:
+		default:
+			return fmt.Errorf("--state must be pending or processing, got %q", cancelState)
+		}
+		if !cancelAll && state == "" && cancelQueue == "" {
+			return errors.New("specify a job id, --state/--queue, or --all")
+		}
+		cancelled, requested, err := st.CancelJobs(ctx, store.JobFilter{State: state, Queue: cancelQueue})
+		if err != nil { return err }
+		fmt.Printf("Cancelled %d pending job(s); requested stop of %d running job(s)\n", cancelled, requested)
+		return nil
+	},
+}
+
+func init() {
+	cancelCmd.Flags().StringVar(&cancelState, "state", "", "Only jobs in this state (pending|processing)")
+	cancelCmd.Flags().StringVar(&cancelQueue, "queue", "", "Only jobs in this queue")
+	cancelCmd.Flags().BoolVar(&cancelAll, "all", false, "Cancel every pending and running job")
+	rootCmd.AddCommand(cancelCmd)
+}
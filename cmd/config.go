@@ -3,22 +3,31 @@ package cmd
 import (
     "context"
     "fmt"
+
+    "github.com/shashidhxr/queueCTL/internal/config"
     "github.com/spf13/cobra"
 )
 
 var configCmd = &cobra.Command{ Use: "config", Short: "Get or set configuration" }
 
 var configGetCmd = &cobra.Command{
-    Use:   "get",
-    Short: "Get all config keys",
+    Use:   "get [key]",
+    Short: "Show effective config values and where they come from (flag, env, db, default)",
+    Args:  cobra.MaximumNArgs(1),
     RunE: func(cmd *cobra.Command, args []string) error {
-        cfg, err := st.GetConfig(context.Background())
+        vals, err := cfg.Values(context.Background())
         if err != nil { 
 			return err
 		}
-        // for k, v := range m { fmt.Printf("%s=%s\n", k, v) }
-		fmt.Printf("max_retries=%d\n", cfg.MaxRetries)
-		fmt.Printf("backoff_base=%d\n", cfg.BackoffBase)
+        found := false
+        for _, v := range vals {
+            if len(args) == 1 && v.Key != args[0] { continue }
+            found = true
+            fmt.Printf("%s=%s (%s)\n", v.Key, v.Value, v.Source)
+        }
+        if !found {
+            return fmt.Errorf("unknown config key %q", args[0])
+        }
         return nil
     },
 }
@@ -28,11 +37,25 @@ var configSetCmd = &cobra.Command{
     Short: "Set a config key",
     Args:  cobra.ExactArgs(2),
     RunE: func(cmd *cobra.Command, args []string) error {
+        if err := config.Validate(args[0], args[1]); err != nil {
+            return err
+        }
         return st.SetConfig(context.Background(), args[0], args[1])
     },
 }
 
+var configKeysCmd = &cobra.Command{
+    Use:   "keys",
+    Short: "List known config keys",
+    RunE: func(cmd *cobra.Command, args []string) error {
+        for _, k := range config.Keys() {
+            fmt.Printf("%-14s default=%-6s env=%s  %s\n", k.Name, k.Default, k.Env(), k.Help)
+        }
+        return nil
+    },
+}
+
 func init() {
-    configCmd.AddCommand(configGetCmd, configSetCmd)
+    configCmd.AddCommand(configGetCmd, configSetCmd, configKeysCmd)
     rootCmd.AddCommand(configCmd)
 }
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shashidhxr/queueCTL/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dbTo    int
+	dbJSON  bool
+	dbForce bool
+)
+
+// schemaTables is what rolling back to version 0 drops.
+const schemaTables = "jobs, job_logs, job_attempts, config, schedules and queues"
+
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Inspect and migrate the database schema",
+	// Other commands migrate on open; these must see the schema as it is.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return openStore(cmd, store.OpenUnmigrated)
+	},
+}
+
+var dbStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show applied and pending schema migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m, err := migrator()
+		if err != nil { return err }
+		ctx := context.Background()
+		states, err := m.MigrationStatus(ctx)
+		if err != nil { return err }
+		if dbJSON {
+			b, _ := json.MarshalIndent(states, "", "  ")
+			fmt.Println(string(b))
+			return nil
+		}
+		cur, latest, err := m.SchemaVersion(ctx)
+		if err != nil { return err }
+		fmt.Printf("schema version %d (this binary knows up to %d)\n", cur, latest)
+		for _, s := range states {
+			status := "pending"
+			switch {
+			case s.Unknown:
+				status = "applied by a newer binary at " + s.AppliedAt.Format(time.RFC3339)
+			case s.AppliedAt != nil:
+				status = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("  %04d_%-20s %s\n", s.Version, s.Name, status)
+		}
+		return nil
+	},
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending migrations (up to --to, default all)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m, err := migrator()
+		if err != nil { return err }
+		ctx := context.Background()
+		cur, latest, err := m.SchemaVersion(ctx)
+		if err != nil { return err }
+		target := latest
+		if cmd.Flags().Changed("to") { target = dbTo }
+		if target < cur {
+			return fmt.Errorf("schema is at version %d; use `db rollback --to %d` to go back", cur, target)
+		}
+		return migrateTo(ctx, m, cur, target)
+	},
+}
+
+var dbRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Roll back the latest migration (or down to --to)",
+	Long: "Roll back the latest migration (or down to --to). Rolling back removes the tables and columns\n" +
+		"the migrations added, with their data. Going below version 1 drops the whole schema and every\n" +
+		"job, so it takes an explicit --to 0 --force.\n\n" +
+		"Every other command of this binary applies pending migrations when it opens the database, so the\n" +
+		"next one re-applies what was rolled back. Roll back to hand the database to an older queuectl\n" +
+		"that only knows the earlier version, and use that binary from then on.",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m, err := migrator()
+		if err != nil { return err }
+		ctx := context.Background()
+		cur, _, err := m.SchemaVersion(ctx)
+		if err != nil { return err }
+		if cur == 0 { return fmt.Errorf("no migrations applied") }
+		target := cur - 1
+		if cmd.Flags().Changed("to") { target = dbTo }
+		if target > cur {
+			return fmt.Errorf("schema is at version %d; use `db migrate --to %d` to go forward", cur, target)
+		}
+		if target < 0 { return fmt.Errorf("--to must be 0 or more") }
+		if target == 0 && (!cmd.Flags().Changed("to") || !dbForce) {
+			return &exitError{code: 1, err: fmt.Errorf("rolling back to version 0 drops %s with all their data; run `db rollback --to 0 --force` to do that", schemaTables)}
+		}
+		states, err := m.MigrationStatus(ctx)
+		if err != nil { return err }
+		for i := len(states) - 1; i >= 0; i-- {
+			if s := states[i]; s.Version > target && s.Version <= cur {
+				fmt.Printf("rolling back %04d_%s\n", s.Version, s.Name)
+			}
+		}
+		if target == 0 { fmt.Printf("dropping %s\n", schemaTables) }
+		return migrateTo(ctx, m, cur, target)
+	},
+}
+
+func migrator() (store.Migrator, error) {
+	m, ok := st.(store.Migrator)
+	if !ok { return nil, fmt.Errorf("%s has no versioned schema", dbPath) }
+	return m, nil
+}
+
+func migrateTo(ctx context.Context, m store.Migrator, from, to int) error {
+	if from == to {
+		fmt.Printf("schema already at version %d\n", to)
+		return nil
+	}
+	if err := m.MigrateTo(ctx, to); err != nil { return err }
+	fmt.Printf("schema migrated from version %d to %d\n", from, to)
+	return nil
+}
+
+func init() {
+	dbStatusCmd.Flags().BoolVar(&dbJSON, "json", false, "Output as JSON")
+	dbMigrateCmd.Flags().IntVar(&dbTo, "to", 0, "Target schema version (default: latest)")
+	dbRollbackCmd.Flags().IntVar(&dbTo, "to", 0, "Target schema version (default: one before the current)")
+	dbRollbackCmd.Flags().BoolVar(&dbForce, "force", false, "Allow --to 0, which drops every table and job")
+	dbCmd.AddCommand(dbStatusCmd, dbMigrateCmd, dbRollbackCmd)
+	rootCmd.AddCommand(dbCmd)
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shashidhxr/queueCTL/internal/store"
+	"github.com/shashidhxr/queueCTL/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dlqQueue     string
+	dlqError     string
+	dlqOlderThan time.Duration
+	dlqLimit     int
+	dlqJSON      bool
+	dlqAll       bool
+)
+
+var dlqCmd = &cobra.Command{
+	Use:   "dlq",
+	Short: "Inspect and recover jobs in the dead letter queue",
+}
+
+var dlqListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List dead jobs",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jobs, err := st.GetDLQJobs(context.Background(), dlqFilter())
+		if err != nil { return err }
+		if dlqJSON {
+			b, _ := json.MarshalIndent(jobs, "", "  ")
+			fmt.Println(string(b))
+			return nil
+		}
+		for _, j := range jobs {
+			fmt.Printf("%s  queue=%s  died=%s  attempts=%d/%d  cmd=%q  err=%q\n",
+				j.ID, j.Queue, j.UpdatedAt.Format(time.RFC3339), j.Attempts, j.MaxRetries, j.Command, j.Error)
+		}
+		return nil
+	},
+}
+
+var dlqInspectCmd = &cobra.Command{
+	Use:   "inspect <job-id>",
+	Short: "Show a dead job, its attempt history and recent logs",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		d, err := loadJobDetail(context.Background(), args[0], 20)
+		if err != nil { return err }
+		if d.Job.State != models.StateDead {
+			return fmt.Errorf("%w: %s is %s", store.ErrNotInDLQ, d.Job.ID, d.Job.State)
+		}
+		return printJobDetail(d, dlqJSON)
+	},
+}
+
+var dlqRetryCmd = &cobra.Command{
+	Use:   "retry [job-id]",
+	Short: "Move a dead job (or every job matching --all and filters) back to pending",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		if len(args) == 1 {
+			if dlqAll { return errors.New("use either a job id or --all, not both") }
+			if err := st.RetryDLQJob(ctx, args[0]); err != nil { return err }
+			fmt.Printf("Requeued: %s\n", args[0])
+			return nil
+		}
+		if !dlqAll { return errors.New("specify a job id or --all") }
+		n, err := st.RetryDLQJobs(ctx, dlqFilter())
+		if err != nil { return err }
+		fmt.Printf("Requeued %d job(s)\n", n)
+		return nil
+	},
+}
+
+var dlqPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Delete dead jobs and their logs",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !dlqAll && dlqQueue == "" && dlqError == "" && dlqOlderThan == 0 {
+			return errors.New("refusing to purge the whole DLQ without --all")
+		}
+		n, err := st.PurgeDLQ(context.Background(), dlqFilter())
+		if err != nil { return err }
+		fmt.Printf("Purged %d job(s)\n", n)
+		return nil
+	},
+}
+
+func dlqFilter() store.DLQFilter {
+	f := store.DLQFilter{Queue: dlqQueue, ErrorContains: dlqError, Limit: dlqLimit}
+	if dlqOlderThan > 0 {
+		f.OlderThan = time.Now().Add(-dlqOlderThan)
+	}
+	return f
+}
+
+func init() {
+	for _, c := range []*cobra.Command{dlqListCmd, dlqRetryCmd, dlqPurgeCmd} {
+		c.Flags().StringVar(&dlqQueue, "queue", "", "Only jobs from this queue")
+		c.Flags().StringVar(&dlqError, "error", "", "Only jobs whose last error contains this text")
+		c.Flags().DurationVar(&dlqOlderThan, "older-than", 0, "Only jobs that died at least this long ago (e.g. 1h)")
+	}
+	dlqListCmd.Flags().IntVar(&dlqLimit, "limit", 50, "Max rows")
+	dlqListCmd.Flags().BoolVar(&dlqJSON, "json", false, "JSON output")
+	dlqInspectCmd.Flags().BoolVar(&dlqJSON, "json", false, "JSON output")
+	dlqRetryCmd.Flags().BoolVar(&dlqAll, "all", false, "Requeue every dead job matching the filters")
+	dlqPurgeCmd.Flags().BoolVar(&dlqAll, "all", false, "Allow purging without any filter")
+
+	dlqCmd.AddCommand(dlqListCmd, dlqInspectCmd, dlqRetryCmd, dlqPurgeCmd)
+	rootCmd.AddCommand(dlqCmd)
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shashidhxr/queueCTL/internal/store"
+	"github.com/shashidhxr/queueCTL/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	inspectLogs int
+	inspectJSON bool
+)
+
+type jobDetail struct {
+	Job      *models.Job      `json:"job"`
+	Attempts []models.Attempt `json:"attempts"`
+	Logs     []store.LogLine  `json:"logs"`
+}
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect <job_id>",
+	Short: "Show every field of a job, its attempt history and the tail of its logs",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		d, err := loadJobDetail(context.Background(), args[0], inspectLogs)
+		if err != nil { return err }
+		return printJobDetail(d, inspectJSON)
+	},
+}
+
+func loadJobDetail(ctx context.Context, id string, logLines int) (*jobDetail, error) {
+	j, err := st.GetJob(ctx, id)
+	if err != nil { return nil, fmt.Errorf("job %s: %w", id, err) }
+	attempts, err := st.GetAttempts(ctx, id)
+	if err != nil { return nil, err }
+	logs, err := st.TailLogs(ctx, id, logLines)
+	if err != nil { return nil, err }
+	return &jobDetail{Job: j, Attempts: attempts, Logs: logs}, nil
+}
+
+func printJobDetail(d *jobDetail, asJSON bool) error {
+	if asJSON {
+		b, _ := json.MarshalIndent(d, "", "  ")
+		fmt.Println(string(b))
+		return nil
+	}
+	j := d.Job
+	fmt.Printf("id:           %s\n", j.ID)
+	fmt.Printf("command:      %s\n", j.Command)
+	fmt.Printf("queue:        %s\n", j.Queue)
+	fmt.Printf("state:        %s\n", j.State)
+	fmt.Printf("priority:     %d\n", j.Priority)
+	fmt.Printf("attempts:     %d/%d\n", j.Attempts, j.MaxRetries)
+	fmt.Printf("timeout:      %s\n", time.Duration(j.TimeoutSeconds)*time.Second)
+	if j.BackoffBase > 0 {
+		fmt.Printf("backoff_base: %g\n", j.BackoffBase)
+	}
+	if j.Backoff != nil {
+		fmt.Printf("backoff:      %s\n", fmtBackoff(*j.Backoff))
+	}
+	if j.RetryDelayMS > 0 {
+		fmt.Printf("retry_delay:  %s\n", fmtMS(j.RetryDelayMS))
+	}
+	if j.RetryPolicy != nil {
+		b, _ := json.Marshal(j.RetryPolicy)
+		fmt.Printf("retry_policy: %s\n", b)
+	}
+	fmt.Printf("created_at:   %s\n", fmtTime(&j.CreatedAt))
+	fmt.Printf("updated_at:   %s\n", fmtTime(&j.UpdatedAt))
+	fmt.Printf("run_at:       %s\n", fmtTime(j.RunAt))
+	fmt.Printf("next_retry:   %s\n", fmtTime(j.NextRetry))
+	owner := "-"
+	if j.WorkerID != nil { owner = *j.WorkerID }
+	fmt.Printf("lease_owner:  %s\n", owner)
+	fmt.Printf("lease_until:  %s\n", fmtTime(j.LeaseUntil))
+	if j.CancelRequested {
+		fmt.Println("cancel:       requested")
+	}
+	fmt.Printf("error:        %s\n", j.Error)
+	if j.Result != "" {
+		// continuation lines of a multi-line result line up under the first
+		result := strings.TrimSuffix(j.Result, "\n")
+		fmt.Printf("result:       %s\n", strings.ReplaceAll(result, "\n", "\n              "))
+	}
+
+	fmt.Println("attempts:")
+	if len(d.Attempts) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, a := range d.Attempts {
+		if a.EndedAt == nil {
+			fmt.Printf("  #%d  start=%s  end=running  duration=%s  worker=%s\n",
+				a.Number, fmtTime(&a.StartedAt), time.Since(a.StartedAt).Round(time.Millisecond), a.Worker)
+			continue
+		}
+		fmt.Printf("  #%d  start=%s  end=%s  %s  worker=%s  err=%q\n",
+			a.Number, fmtTime(&a.StartedAt), fmtTime(a.EndedAt), fmtUsage(a.Usage), a.Worker, a.Error)
+	}
+
+	fmt.Println("logs:")
+	for _, l := range d.Logs {
+		printLogChunk("  ", l)
+	}
+	return nil
+}
+
+// fmtUsage renders how an attempt ended: its exit code or signal, wall and
+// CPU (user/sys) time and peak memory.
+func fmtUsage(u models.Usage) string {
+	end := "exit=-"
+	switch {
+	case u.Signal != "":
+		end = "signal=" + u.Signal
+	case u.ExitCode != nil:
+		end = fmt.Sprintf("exit=%d", *u.ExitCode)
+	}
+	return fmt.Sprintf("%s  wall=%s  cpu=%s/%s  rss=%s", end, fmtMS(u.WallMS), fmtMS(u.UserMS), fmtMS(u.SysMS), fmtKB(u.MaxRSSKB))
+}
+
+func fmtMS(ms int64) string { return (time.Duration(ms) * time.Millisecond).String() }
+
+func fmtKB(kb int64) string {
+	if kb >= 1024 { return fmt.Sprintf("%.1fMB", float64(kb)/1024) }
+	return fmt.Sprintf("%dKB", kb)
+}
+
+func fmtTime(t *time.Time) string {
+	if t == nil || t.IsZero() { return "-" }
+	return t.Local().Format(time.RFC3339)
+}
+
+func init() {
+	inspectCmd.Flags().IntVar(&inspectLogs, "logs", 20, "Number of trailing log chunks to show")
+	inspectCmd.Flags().BoolVar(&inspectJSON, "json", false, "JSON output")
+	rootCmd.AddCommand(inspectCmd)
+}
@@ -5,37 +5,68 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"time"
 
+	"github.com/shashidhxr/queueCTL/internal/store"
 	"github.com/shashidhxr/queueCTL/pkg/models"
 	"github.com/spf13/cobra"
 )
 
 var listState string
+var listQueue string
 var listLimit int
 var listJSON bool
 
 var listCmd = &cobra.Command{
     Use:   "list",
-    Short: "List jobs (optionally by state)",
+    Short: "List jobs (optionally by state and queue), grouped by queue",
     RunE: func(cmd *cobra.Command, args []string) error {
-        state := models.JobState(listState) // "" means all
-        jobs, err := st.ListJobs(context.Background(), state, listLimit)
+        jobs, err := st.ListJobs(context.Background(), store.JobFilter{
+            State: models.JobState(listState), // "" means all
+            Queue: listQueue,
+            Limit: listLimit,
+        })
         if err != nil { return err }
         if listJSON {
             b, _ := json.MarshalIndent(jobs, "", "  ")
             fmt.Println(string(b))
             return nil
         }
-        for _, j := range jobs {
-            fmt.Printf("%s  %-10s  attempts=%d/%d  cmd=%q  err=%q\n",
-                j.ID, j.State, j.Attempts, j.MaxRetries, j.Command, j.Error)
+        // newest first within each queue
+        sort.SliceStable(jobs, func(a, b int) bool {
+            if jobs[a].Queue != jobs[b].Queue { return jobs[a].Queue < jobs[b].Queue }
+            return jobs[a].CreatedAt.After(jobs[b].CreatedAt)
+        })
+        for i, j := range jobs {
+            if i == 0 || jobs[i-1].Queue != j.Queue {
+                fmt.Printf("== queue %s ==\n", j.Queue)
+            }
+            sched := ""
+            if j.RunAt != nil {
+                sched = "  run_at=" + j.RunAt.Local().Format(time.RFC3339)
+            }
+            backoff := ""
+            if j.BackoffBase > 0 {
+                backoff = fmt.Sprintf("  backoff=%g", j.BackoffBase)
+            }
+            if j.Backoff != nil {
+                backoff += fmt.Sprintf("  backoff_policy=%q", fmtBackoff(*j.Backoff))
+            }
+            last := ""
+            if a := j.LastAttempt; a != nil && a.EndedAt != nil {
+                last = "  last: " + fmtUsage(a.Usage)
+            }
+            fmt.Printf("%s  %-10s  prio=%d  attempts=%d/%d  timeout=%s%s%s%s  cmd=%q  err=%q\n",
+                j.ID, j.State, j.Priority, j.Attempts, j.MaxRetries, time.Duration(j.TimeoutSeconds)*time.Second, backoff, sched, last, j.Command, j.Error)
         }
         return nil
     },
 }
 
 func init() {
-    listCmd.Flags().StringVar(&listState, "state", "", "Filter by state (pending|processing|completed|failed|dead)")
+    listCmd.Flags().StringVar(&listState, "state", "", "Filter by state (pending|scheduled|processing|completed|failed|dead|cancelled)")
+    listCmd.Flags().StringVar(&listQueue, "queue", "", "Filter by queue")
     listCmd.Flags().IntVar(&listLimit, "limit", 50, "Max rows")
     listCmd.Flags().BoolVar(&listJSON, "json", false, "JSON output")
     rootCmd.AddCommand(listCmd)
@@ -3,28 +3,106 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/shashidhxr/queueCTL/internal/store"
 	"github.com/spf13/cobra"
 )
 
-var logsLimit int
+var (
+	logsLimit   int
+	logsFollow  bool
+	logsStream  string
+	logsSince   string
+	logsAttempt int
+)
 
 var logsCmd = &cobra.Command{
     Use:   "logs <job_id>",
     Short: "Print stored logs for a job",
+    Long: `Print stored logs for a job.
+
+With --follow, keep printing new output as the job writes it (like tail -f)
+until the job reaches a terminal state (completed, dead or cancelled).`,
     Args:  cobra.ExactArgs(1),
     RunE: func(cmd *cobra.Command, args []string) error {
-        lines, err := st.GetLogs(context.Background(), args[0], logsLimit)
+        f := store.LogFilter{Stream: logsStream, Attempt: logsAttempt, Limit: logsLimit}
+        switch logsStream {
+        case "", "stdout", "stderr":
+        default:
+            return fmt.Errorf("invalid --stream %q (want stdout or stderr)", logsStream)
+        }
+        if logsSince != "" {
+            t, err := parseSince(logsSince)
+            if err != nil { return err }
+            f.Since = t
+        }
+
+        ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+        defer stop()
+        id := args[0]
+        for {
+            // Check the state before reading so the last read sees every
+            // chunk written before the job finished.
+            j, err := st.GetJob(ctx, id)
+            if err != nil { return fmt.Errorf("job %s: %w", id, err) }
+            done := j.State.Terminal()
+
+            if err := printLogs(ctx, id, &f, logsFollow); err != nil { return err }
+            if !logsFollow || done { return nil }
+
+            select {
+            case <-ctx.Done():
+                return nil
+            case <-time.After(500 * time.Millisecond):
+            }
+        }
+    },
+}
+
+// parseSince accepts a duration back from now (10m) or an RFC3339 time.
+func parseSince(s string) (time.Time, error) {
+    if d, err := time.ParseDuration(s); err == nil {
+        return time.Now().Add(-d), nil
+    }
+    t, err := time.Parse(time.RFC3339, s)
+    if err != nil { return time.Time{}, fmt.Errorf("invalid --since %q (want a duration like 10m or an RFC3339 time)", s) }
+    return t, nil
+}
+
+// printLogs prints a job's chunks matching f after f.AfterID and advances
+// f.AfterID past them. It reads f.Limit chunks at a time, and only once
+// unless all is set.
+func printLogs(ctx context.Context, id string, f *store.LogFilter, all bool) error {
+    for {
+        lines, err := st.GetLogs(ctx, id, *f)
         if err != nil { return err }
         for _, l := range lines {
-            fmt.Printf("%s [%s] %s\n", l.TS.Format(time.RFC3339), l.Stream, l.Chunk)
+            printLogChunk("", l)
+            f.AfterID = l.ID
         }
-        return nil
-    },
+        if !all || len(lines) < f.Limit { return nil }
+    }
+}
+
+// printLogChunk prints a stored chunk line by line, each prefixed with its
+// timestamp, attempt and stream; a chunk may hold many lines or end mid-line.
+func printLogChunk(indent string, l store.LogLine) {
+    tag := l.Stream
+    if l.Attempt > 0 { tag = fmt.Sprintf("#%d %s", l.Attempt, l.Stream) }
+    for _, line := range strings.Split(strings.TrimSuffix(l.Chunk, "\n"), "\n") {
+        fmt.Printf("%s%s [%s] %s\n", indent, l.TS.Local().Format(time.RFC3339), tag, line)
+    }
 }
 
 func init() {
-    logsCmd.Flags().IntVar(&logsLimit, "limit", 200, "Max lines")
+    logsCmd.Flags().IntVar(&logsLimit, "limit", 200, "Max chunks (per read when following)")
+    logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Keep streaming new output until the job finishes")
+    logsCmd.Flags().StringVar(&logsStream, "stream", "", "Only show stdout or stderr")
+    logsCmd.Flags().StringVar(&logsSince, "since", "", "Only show output newer than a duration (10m) or RFC3339 time")
+    logsCmd.Flags().IntVar(&logsAttempt, "attempt", 0, "Only show output of attempt N")
     rootCmd.AddCommand(logsCmd)
 }
@@ -3,34 +3,152 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/shashidhxr/queueCTL/internal/core"
 	"github.com/shashidhxr/queueCTL/pkg/models"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
-var jobQueue = make([]*models.Job, 0)
-var queueLock sync.Mutex
+var (
+	enqueueRunAt string
+	enqueueDelay time.Duration
+	enqueuePrio  int
+	enqueueQueue string
+
+	enqueueTimeout    time.Duration
+	enqueueMaxRetries int // read through cfg (max_retries)
+	enqueueBackoff    float64
+	enqueueRetry      retryPolicyFlags
+	enqueueBackoffs   backoffFlags
+	enqueueWait       bool
+)
 
 var enqueueCmd = &cobra.Command{
 	Use:   "enqueue [command]",
 	Short: "Enqueue a job",
 	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		j := &models.Job{
-			ID:         uuid.NewString(),
-			Command:    args[0],
-			State:      models.StatePending,
-			MaxRetries: 3,
+		j, err := newJob(cmd, args[0])
+		if err != nil {
+			return err
 		}
 		if err := st.SaveJob(context.Background(), j); err != nil {
 			return err
 		}
-		fmt.Printf("Enqueued: %+v\n", *j)
-		return nil
+		if j.RunAt != nil {
+			fmt.Printf("Enqueued: %s  queue=%s  state=%s  run_at=%s\n", j.ID, j.Queue, models.StateScheduled, fmtTime(j.RunAt))
+		} else {
+			fmt.Printf("Enqueued: %s  queue=%s  state=%s\n", j.ID, j.Queue, j.State)
+		}
+		if !enqueueWait {
+			return nil
+		}
+		// From here on an error is the job's outcome, not a usage problem.
+		cmd.SilenceUsage, cmd.SilenceErrors = true, true
+		return followJob(j.ID)
 	},
 }
 
-func init() { rootCmd.AddCommand(enqueueCmd) }
+// newJob builds a pending job running command from the job flags (see
+// addJobFlags) of cmd.
+func newJob(cmd *cobra.Command, command string) (*models.Job, error) {
+	runAt, err := enqueueSchedule()
+	if err != nil {
+		return nil, err
+	}
+	c, err := cfg.Resolve(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	timeout := c.JobTimeout
+	if cmd.Flags().Changed("timeout") {
+		if enqueueTimeout < time.Second {
+			return nil, errors.New("--timeout must be at least 1s")
+		}
+		timeout = enqueueTimeout
+	}
+	if cmd.Flags().Changed("backoff") && enqueueBackoff < 1 {
+		return nil, errors.New("--backoff must be >= 1")
+	}
+	backoff := enqueueBackoffs.policy(cmd.Flags())
+	if cmd.Flags().Changed("backoff") {
+		// --backoff is the base of the job's backoff policy, so the two can't disagree
+		if backoff == nil { backoff = &models.BackoffPolicy{} }
+		backoff.Base = enqueueBackoff
+	}
+	if backoff != nil {
+		q, err := st.GetQueueSettings(context.Background(), enqueueQueue)
+		if err != nil {
+			return nil, err
+		}
+		if err := core.NewRetryManager(st, cfg).CheckBackoff(q.Backoff, backoff); err != nil {
+			return nil, err
+		}
+	}
+	policy, err := enqueueRetry.policy(cmd.Flags())
+	if err != nil {
+		return nil, err
+	}
+	j := &models.Job{
+		ID:         uuid.NewString(),
+		Command:    command,
+		Queue:      enqueueQueue,
+		State:      models.StatePending,
+		MaxRetries: c.MaxRetries,
+		RunAt:      runAt,
+		Priority:   enqueuePrio,
+
+		TimeoutSeconds: int(timeout.Round(time.Second) / time.Second),
+		RetryPolicy:    policy,
+		Backoff:        backoff,
+	}
+	return j, nil
+}
+
+// enqueueSchedule turns --run-at / --delay into the job's run_at, or nil when
+// the job should be eligible immediately.
+func enqueueSchedule() (*time.Time, error) {
+	if enqueueRunAt != "" && enqueueDelay != 0 {
+		return nil, errors.New("use either --run-at or --delay, not both")
+	}
+	if enqueueRunAt != "" {
+		t, err := time.Parse(time.RFC3339, enqueueRunAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --run-at: %w", err)
+		}
+		t = t.UTC()
+		return &t, nil
+	}
+	if enqueueDelay < 0 {
+		return nil, errors.New("--delay must not be negative")
+	}
+	if enqueueDelay > 0 {
+		t := time.Now().UTC().Add(enqueueDelay)
+		return &t, nil
+	}
+	return nil, nil
+}
+
+// addJobFlags registers the flags newJob reads; enqueue and run share them.
+func addJobFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&enqueueRunAt, "run-at", "", "Do not run before this time (RFC3339, e.g. 2025-01-02T03:00:00Z)")
+	fs.DurationVar(&enqueueDelay, "delay", 0, "Do not run before now+delay (e.g. 10m)")
+	fs.DurationVar(&enqueueTimeout, "timeout", 0, "Kill the job after this long (default: config job_timeout)")
+	fs.IntVar(&enqueueMaxRetries, "max-retries", 0, "Retries before the job moves to the DLQ (default: config max_retries)")
+	fs.Float64Var(&enqueueBackoff, "backoff", 0, "Growth factor of this job's exponential and full-jitter backoff (default: queue's, then config backoff_base)")
+	enqueueRetry.register(fs)
+	enqueueBackoffs.register(fs)
+	fs.StringVar(&enqueueQueue, "queue", models.DefaultQueue, "Queue to put the job on")
+	fs.IntVar(&enqueuePrio, "priority", 0, "Job priority; higher values are picked up first")
+}
+
+func init() {
+	addJobFlags(enqueueCmd.Flags())
+	enqueueCmd.Flags().BoolVar(&enqueueWait, "wait", false, "Stream the job's logs until it finishes and exit like the wait command")
+	rootCmd.AddCommand(enqueueCmd)
+}
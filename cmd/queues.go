@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shashidhxr/queueCTL/internal/core"
+	"github.com/shashidhxr/queueCTL/pkg/models"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// retryPolicyFlags are the retry policy flags shared by `enqueue` and
+// `queues set`.
+type retryPolicyFlags struct {
+	retryOn, fatalOn       []int
+	fatalMatch, retryMatch string
+}
+
+func (f *retryPolicyFlags) register(fs *pflag.FlagSet) {
+	fs.IntSliceVar(&f.retryOn, "retry-on", nil, "Only retry these exit codes (e.g. 1,75)")
+	fs.IntSliceVar(&f.fatalOn, "fatal-on", nil, "Never retry these exit codes (e.g. 2,126,127)")
+	fs.StringVar(&f.fatalMatch, "fatal-match", "", "Never retry when stderr matches this regexp")
+	fs.StringVar(&f.retryMatch, "retry-match", "", "Always retry when stderr matches this regexp, whatever the exit code")
+}
+
+// policy returns the policy described by the flags, or nil if none was given.
+func (f *retryPolicyFlags) policy(fs *pflag.FlagSet) (*models.RetryPolicy, error) {
+	changed := false
+	for _, name := range []string{"retry-on", "fatal-on", "fatal-match", "retry-match"} {
+		changed = changed || fs.Changed(name)
+	}
+	if !changed { return nil, nil }
+	p := &models.RetryPolicy{RetryOn: f.retryOn, FatalOn: f.fatalOn, FatalMatch: f.fatalMatch, RetryMatch: f.retryMatch}
+	if err := core.ValidateRetryPolicy(p); err != nil { return nil, err }
+	return p, nil
+}
+
+// backoffFlags are the backoff policy flags shared by `enqueue` and
+// `queues set`. Their names match config keys, so the resolver also binds them.
+type backoffFlags struct {
+	strategy string
+	min, max time.Duration
+	base     float64
+}
+
+func (f *backoffFlags) register(fs *pflag.FlagSet) {
+	fs.StringVar(&f.strategy, "backoff-strategy", "", "Retry delay strategy: fixed|linear|exponential|decorrelated-jitter|full-jitter (default: config backoff_strategy)")
+	fs.DurationVar(&f.min, "backoff-min", 0, "Shortest retry delay; also the fixed delay, linear step and exponential unit (default: config backoff_min)")
+	fs.DurationVar(&f.max, "backoff-max", 0, "Longest retry delay (default: config backoff_max)")
+}
+
+// policy returns the fields set on the command line, or nil if none was.
+func (f *backoffFlags) policy(fs *pflag.FlagSet) *models.BackoffPolicy {
+	var p models.BackoffPolicy
+	if fs.Changed("backoff-strategy") { p.Strategy = f.strategy }
+	if fs.Changed("backoff-min") { p.MinMS = f.min.Milliseconds() }
+	if fs.Changed("backoff-max") { p.MaxMS = f.max.Milliseconds() }
+	if fs.Changed("backoff-base") { p.Base = f.base }
+	if p == (models.BackoffPolicy{}) { return nil }
+	return &p
+}
+
+var (
+	queueRetryFlags   retryPolicyFlags
+	queueBackoffFlags backoffFlags
+	queuesJSON        bool
+)
+
+var queuesCmd = &cobra.Command{
+	Use:   "queues",
+	Short: "Manage per-queue settings (retry policy, backoff) used by jobs that don't set their own",
+}
+
+var queuesSetCmd = &cobra.Command{
+	Use:   "set <queue>",
+	Short: "Set a queue's retry policy and/or backoff",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		q, err := st.GetQueueSettings(ctx, args[0])
+		if err != nil { return err }
+		p, err := queueRetryFlags.policy(cmd.Flags())
+		if err != nil { return err }
+		b := queueBackoffFlags.policy(cmd.Flags())
+		if p == nil && b == nil { return fmt.Errorf("nothing to set; see --help") }
+		if p != nil { q.RetryPolicy = p }
+		if b != nil {
+			if err := core.NewRetryManager(st, cfg).CheckBackoff(b); err != nil { return err }
+			q.Backoff = b
+		}
+		if err := st.SaveQueueSettings(ctx, q); err != nil { return err }
+		printQueueSettings(*q)
+		return nil
+	},
+}
+
+var queuesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show configured queues",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		qs, err := st.ListQueueSettings(context.Background())
+		if err != nil { return err }
+		if queuesJSON {
+			b, _ := json.MarshalIndent(qs, "", "  ")
+			fmt.Println(string(b))
+			return nil
+		}
+		for _, q := range qs { printQueueSettings(q) }
+		return nil
+	},
+}
+
+var queuesClearCmd = &cobra.Command{
+	Use:   "clear <queue>",
+	Short: "Remove a queue's settings (its jobs are kept)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return st.DeleteQueueSettings(context.Background(), args[0])
+	},
+}
+
+func printQueueSettings(q models.QueueSettings) {
+	retry := "-"
+	if p := q.RetryPolicy; p != nil {
+		b, _ := json.Marshal(p)
+		retry = string(b)
+	}
+	backoff := "-"
+	if b := q.Backoff; b != nil {
+		backoff = fmtBackoff(*b)
+	}
+	fmt.Printf("%-16s retry=%s  backoff=%s\n", q.Name, retry, backoff)
+}
+
+// fmtBackoff renders the fields a backoff policy sets, e.g. "full-jitter min=1s".
+func fmtBackoff(b models.BackoffPolicy) string {
+	var parts []string
+	if b.Strategy != "" { parts = append(parts, b.Strategy) }
+	if b.Base > 0 { parts = append(parts, fmt.Sprintf("base=%g", b.Base)) }
+	if b.MinMS > 0 { parts = append(parts, "min="+fmtMS(b.MinMS)) }
+	if b.MaxMS > 0 { parts = append(parts, "max="+fmtMS(b.MaxMS)) }
+	return strings.Join(parts, " ")
+}
+
+func init() {
+	queueRetryFlags.register(queuesSetCmd.Flags())
+	queueBackoffFlags.register(queuesSetCmd.Flags())
+	queuesSetCmd.Flags().Float64Var(&queueBackoffFlags.base, "backoff-base", 0, "Growth factor of exponential and full-jitter backoff (default: config backoff_base)")
+	queuesListCmd.Flags().BoolVar(&queuesJSON, "json", false, "JSON output")
+
+	queuesCmd.AddCommand(queuesSetCmd, queuesListCmd, queuesClearCmd)
+	rootCmd.AddCommand(queuesCmd)
+}
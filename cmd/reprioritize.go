@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var reprioritizeCmd = &cobra.Command{
+	Use:   "reprioritize <job_id> <priority>",
+	Short: "Change the priority of a pending job",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := strconv.Atoi(args[1])
+		if err != nil { return fmt.Errorf("invalid priority %q: %w", args[1], err) }
+		if err := st.SetPriority(context.Background(), args[0], p); err != nil { return err }
+		fmt.Printf("Job %s priority=%d\n", args[0], p)
+		return nil
+	},
+}
+
+func init() { rootCmd.AddCommand(reprioritizeCmd) }
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/shashidhxr/queueCTL/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var resultCmd = &cobra.Command{
+	Use:   "result <job_id>",
+	Short: "Print the result a completed job reported",
+	Long: "Print the result a completed job reported, exactly as stored. A job reports a result by writing it\n" +
+		"to the file named by $QUEUECTL_RESULT_FILE, or by printing a line \"##queuectl result <value>\".\n" +
+		"Fails if the job has not completed; prints nothing if it completed without a result.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		j, err := st.GetJob(context.Background(), args[0])
+		if err != nil { return fmt.Errorf("job %s: %w", args[0], err) }
+		if j.State != models.StateCompleted {
+			return fmt.Errorf("job %s is %s; only completed jobs have a result", j.ID, j.State)
+		}
+		if j.Result == "" { return nil }
+		fmt.Print(j.Result)
+		if !strings.HasSuffix(j.Result, "\n") { fmt.Println() }
+		return nil
+	},
+}
+
+func init() { rootCmd.AddCommand(resultCmd) }
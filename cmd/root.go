@@ -1,41 +1,70 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/shashidhxr/queueCTL/internal/config"
 	"github.com/shashidhxr/queueCTL/internal/store"
 	"github.com/spf13/cobra"
 )
 
 var (
 	dbPath string
-	st     *store.SQLiteStorage
+	st     store.Store
+	cfg    *config.Resolver
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "queuectl",
 	Short: "A CLI based background job queue system.",
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		if st != nil { return nil }
-		if dbPath == "" {
-			home, _ := os.UserHomeDir()
-			dbPath = filepath.Join(home, ".queuectl", "queue.db")
-		}
-		s, err := store.NewSQLiteStorage(dbPath)
-		if err != nil { return err }
-		st = s
-		return nil
+		return openStore(cmd, store.Open)
 	},
 }
 
+// openStore sets up st and cfg for cmd with the given opener, unless a store
+// was already provided.
+func openStore(cmd *cobra.Command, open func(dsn string) (store.Store, error)) error {
+	if st != nil { return nil }
+	if dbPath == "" {
+		home, _ := os.UserHomeDir()
+		dbPath = filepath.Join(home, ".queuectl", "queue.db")
+	}
+	s, err := open(dbPath)
+	if err != nil { return err }
+	st = s
+	cfg = config.NewResolver(st)
+	cfg.BindFlags(cmd.Flags()) // e.g. --max-retries overrides max_retries
+	return nil
+}
+
+// exitError makes Execute exit with code, after printing err if there is one.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil { return fmt.Sprintf("exit status %d", e.code) }
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error { return e.err }
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
+		var ee *exitError
+		if errors.As(err, &ee) {
+			if ee.err != nil { fmt.Println(ee.err) }
+			os.Exit(ee.code)
+		}
         fmt.Println(err)
     }
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVar(&dbPath, "db", "", "Path to SQLite DB (default $HOME/.queuectl/queue.db)")
+	rootCmd.PersistentFlags().StringVar(&dbPath, "db", "", "SQLite DB path, postgres:// DSN or memory:// (default $HOME/.queuectl/queue.db)")
 }
\ No newline at end of file
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/shashidhxr/queueCTL/internal/core"
+	"github.com/shashidhxr/queueCTL/internal/store"
+	"github.com/shashidhxr/queueCTL/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run <command>",
+	Short: "Run a job in this process, with a worker's retries, timeouts and logging",
+	Long: `Run a job in this process, through the same code path a worker uses: the same timeout, retry
+policy, backoff, output capture and result handling, without a separate worker. Output is
+streamed to the terminal and the exit code is that of ` + "`wait`" + `.
+
+The job is saved in --db like any other, so config, queue settings and retry policies apply and
+list, inspect, logs and result see it. This process leases only that job; a worker serving its
+queue could still take an attempt, in which case run waits for it without showing its output.
+Interrupting run stops the command and cancels the job.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		j, err := newJob(cmd, args[0])
+		if err != nil { return &exitError{code: 1, err: err} }
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		if err := st.SaveJob(ctx, j); err != nil { return &exitError{code: 1, err: err} }
+
+		host, _ := os.Hostname()
+		workerHost = fmt.Sprintf("%s:%d", host, os.Getpid())
+
+		workCtx, cancel := context.WithCancel(ctx)
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			runOnly(workCtx, j.ID, core.NewRetryManager(st, cfg))
+		}()
+
+		code := 0
+		err = waitFor(ctx, []string{j.ID}, func(j *models.Job) error {
+			printJobEnd(j)
+			if j.Result != "" {
+				fmt.Println("result:", strings.TrimSuffix(j.Result, "\n"))
+			}
+			code = jobExitCode(j.State)
+			return nil
+		})
+		cancel()
+		<-stopped
+		if ctx.Err() != nil {
+			// Interrupted: don't leave the job for some worker to run later. A
+			// job still marked running is cancelled when its lease expires.
+			_, _ = st.CancelJob(context.Background(), j.ID)
+		}
+		if err != nil { return waitError(err) }
+		if code != 0 { return &exitError{code: code} }
+		return nil
+	},
+}
+
+// runOnly works like a one-goroutine worker that serves only job id: it
+// leases the job whenever it is due, runs it and records the outcome, until
+// ctx is done.
+func runOnly(ctx context.Context, id string, rm *core.RetryManager) {
+	var wake <-chan struct{}
+	if w, ok := st.(store.Waker); ok {
+		wake = w.JobsAvailable()
+	}
+	for {
+		j, err := st.AcquireJobByID(ctx, id)
+		if err != nil && ctx.Err() == nil {
+			fmt.Printf("[worker 1] acquire error: %v\n", err)
+		}
+		if j != nil {
+			runJob(ctx, 1, rm, j)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+		case <-time.After(pollInterval): // e.g. waiting out a retry's backoff
+		}
+	}
+}
+
+func init() {
+	addJobFlags(runCmd.Flags())
+	rootCmd.AddCommand(runCmd)
+}
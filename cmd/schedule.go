@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shashidhxr/queueCTL/internal/core"
+	"github.com/shashidhxr/queueCTL/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	scheduleCatchUp    string
+	scheduleQueue      string
+	scheduleMaxRetries int // bound to the max_retries config key
+	scheduleJSON       bool
+)
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Manage recurring jobs",
+}
+
+var scheduleAddCmd = &cobra.Command{
+	Use:   "add <spec> <command>",
+	Short: `Add a recurring job ("*/5 * * * *", "@hourly", "@every 30s"; cron is evaluated in UTC)`,
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		policy := models.CatchUpPolicy(scheduleCatchUp)
+		switch policy {
+		case models.CatchUpSkip, models.CatchUpRunOnce, models.CatchUpRunAll:
+		default:
+			return fmt.Errorf("invalid --catch-up %q (skip|run-once|run-all)", scheduleCatchUp)
+		}
+		spec, err := core.ParseSchedule(args[0])
+		if err != nil { return err }
+		next := spec.Next(time.Now())
+		if next.IsZero() {
+			return fmt.Errorf("schedule %q never fires", args[0])
+		}
+		c, err := cfg.Resolve(context.Background())
+		if err != nil { return err }
+		sc := &models.Schedule{
+			ID:         uuid.NewString(),
+			Spec:       args[0],
+			Command:    args[1],
+			Queue:      scheduleQueue,
+			MaxRetries: c.MaxRetries,
+			CatchUp:    policy,
+			NextRunAt:  next,
+		}
+		if err := st.SaveSchedule(context.Background(), sc); err != nil { return err }
+		fmt.Printf("Scheduled: %s (next run %s)\n", sc.ID, sc.NextRunAt.Format(time.RFC3339))
+		return nil
+	},
+}
+
+var scheduleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recurring jobs",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scs, err := st.ListSchedules(context.Background())
+		if err != nil { return err }
+		if scheduleJSON {
+			b, _ := json.MarshalIndent(scs, "", "  ")
+			fmt.Println(string(b))
+			return nil
+		}
+		for _, sc := range scs {
+			state := "active"
+			if sc.Paused { state = "paused" }
+			last := "never"
+			if sc.LastRunAt != nil { last = sc.LastRunAt.Format(time.RFC3339) }
+			fmt.Printf("%s  %-6s  queue=%s  spec=%q  next=%s  last=%s  catch_up=%s  cmd=%q\n",
+				sc.ID, state, sc.Queue, sc.Spec, sc.NextRunAt.Format(time.RFC3339), last, sc.CatchUp, sc.Command)
+		}
+		return nil
+	},
+}
+
+var schedulePauseCmd = &cobra.Command{
+	Use:   "pause <schedule-id>",
+	Short: "Stop creating jobs for a schedule",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return st.PauseSchedule(context.Background(), args[0])
+	},
+}
+
+var scheduleResumeCmd = &cobra.Command{
+	Use:   "resume <schedule-id>",
+	Short: "Resume a paused schedule from its next tick",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		sc, err := st.GetSchedule(ctx, args[0])
+		if err != nil { return err }
+		spec, err := core.ParseSchedule(sc.Spec)
+		if err != nil { return err }
+		return st.ResumeSchedule(ctx, sc.ID, spec.Next(time.Now()))
+	},
+}
+
+var scheduleDeleteCmd = &cobra.Command{
+	Use:   "delete <schedule-id>",
+	Short: "Delete a schedule (jobs it already created are kept)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return st.DeleteSchedule(context.Background(), args[0])
+	},
+}
+
+func init() {
+	scheduleAddCmd.Flags().StringVar(&scheduleCatchUp, "catch-up", string(models.CatchUpSkip), "Missed ticks after downtime: skip|run-once|run-all")
+	scheduleAddCmd.Flags().StringVar(&scheduleQueue, "queue", models.DefaultQueue, "Queue for each created job")
+	scheduleAddCmd.Flags().IntVar(&scheduleMaxRetries, "max-retries", 0, "Max retries for each created job (default: config max_retries)")
+	scheduleListCmd.Flags().BoolVar(&scheduleJSON, "json", false, "JSON output")
+
+	scheduleCmd.AddCommand(scheduleAddCmd, scheduleListCmd, schedulePauseCmd, scheduleResumeCmd, scheduleDeleteCmd)
+	rootCmd.AddCommand(scheduleCmd)
+}
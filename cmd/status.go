@@ -3,6 +3,8 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/shashidhxr/queueCTL/pkg/models"
 	"github.com/spf13/cobra"
@@ -10,14 +12,48 @@ import (
 
 var statusCmd = &cobra.Command{
     Use:   "status",
-    Short: "Print counts of jobs by state",
+    Short: "Print counts of jobs by state, overall and per queue",
     RunE: func(cmd *cobra.Command, args []string) error {
-        m, err := st.GetJobStats(context.Background())
+        ctx := context.Background()
+        m, err := st.GetJobStats(ctx)
         if err != nil { return err }
-        fmt.Printf("pending=%d processing=%d completed=%d failed=%d dead=%d\n",
-            m[models.StatePending], m[models.StateProcessing], m[models.StateCompleted], m[models.StateFailed], m[models.StateDead])
+        printStateCounts("total", m)
+
+        byQueue, err := st.GetQueueStats(ctx)
+        if err != nil { return err }
+        names := make([]string, 0, len(byQueue))
+        for q := range byQueue { names = append(names, q) }
+        sort.Strings(names)
+        for _, q := range names {
+            printStateCounts("queue="+q, byQueue[q])
+        }
+
+        usage, err := st.GetAttemptStats(ctx, time.Now().Add(-statusWindow))
+        if err != nil { return err }
+        if len(usage) > 0 {
+            fmt.Printf("\nattempts in the last %s:\n", statusWindow)
+        }
+        names = names[:0]
+        for q := range usage { names = append(names, q) }
+        sort.Strings(names)
+        for _, q := range names {
+            a := usage[q]
+            fmt.Printf("%-16s runs=%d ok=%d failed=%d timed_out=%d killed=%d wall(avg/max)=%s/%s cpu(user/sys)=%s/%s max_rss=%s\n",
+                "queue="+q, a.Attempts, a.Succeeded, a.Failed, a.TimedOut, a.Killed,
+                fmtMS(a.AvgWallMS), fmtMS(a.MaxWallMS), fmtMS(a.UserMS), fmtMS(a.SysMS), fmtKB(a.MaxRSSKB))
+        }
         return nil
     },
 }
 
-func init() { rootCmd.AddCommand(statusCmd) }
+func printStateCounts(label string, m map[models.JobState]int) {
+    fmt.Printf("%-16s pending=%d scheduled=%d processing=%d completed=%d failed=%d dead=%d cancelled=%d\n", label,
+        m[models.StatePending], m[models.StateScheduled], m[models.StateProcessing], m[models.StateCompleted], m[models.StateFailed], m[models.StateDead], m[models.StateCancelled])
+}
+
+var statusWindow time.Duration
+
+func init() {
+    statusCmd.Flags().DurationVar(&statusWindow, "window", 24*time.Hour, "Summarize attempts that ended within this window")
+    rootCmd.AddCommand(statusCmd)
+}
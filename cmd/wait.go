@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/shashidhxr/queueCTL/internal/store"
+	"github.com/shashidhxr/queueCTL/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	waitTimeout time.Duration
+	waitLogs    bool
+	waitPoll    time.Duration
+)
+
+// Exit codes of wait (and of enqueue --wait and run). When jobs end
+// differently the highest code wins.
+const (
+	exitCancelled   = 2
+	exitDead        = 3 // dead or failed
+	exitTimeout     = 124
+	exitInterrupted = 130
+)
+
+var waitCmd = &cobra.Command{
+	Use:   "wait <job_id>...",
+	Short: "Block until jobs finish; the exit code tells how they ended",
+	Long: `Block until every given job reaches a terminal state (completed, dead or cancelled),
+printing each one's final state as it finishes.
+
+Exit codes: 0 all jobs completed, 1 an error (e.g. unknown job), 2 a job was cancelled,
+3 a job is dead or failed, 124 --timeout expired first, 130 interrupted.`,
+	Args:          cobra.MinimumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		if waitTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, waitTimeout)
+			defer cancel()
+		}
+
+		code := 0
+		err := waitFor(ctx, args, func(j *models.Job) error {
+			if waitLogs {
+				f := store.LogFilter{Limit: 200}
+				if err := printLogs(ctx, j.ID, &f, true); err != nil { return err }
+			}
+			printJobEnd(j)
+			code = max(code, jobExitCode(j.State))
+			return nil
+		})
+		if err != nil { return waitError(err) }
+		if code != 0 { return &exitError{code: code} }
+		return nil
+	},
+}
+
+// waitFor polls the jobs in ids every --poll until each is in a terminal
+// state, calling done for each one as it finishes. It returns early with
+// ctx's error, or with the first error from the store or done.
+func waitFor(ctx context.Context, ids []string, done func(j *models.Job) error) error {
+	pending := slices.Clone(ids)
+	for {
+		rest := pending[:0]
+		for _, id := range pending {
+			j, err := st.GetJob(ctx, id)
+			if ctx.Err() != nil { return ctx.Err() }
+			if err != nil { return fmt.Errorf("job %s: %w", id, err) }
+			if !j.State.Terminal() {
+				rest = append(rest, id)
+				continue
+			}
+			if err := done(j); err != nil { return err }
+		}
+		if pending = rest; len(pending) == 0 { return nil }
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(waitPoll):
+		}
+	}
+}
+
+// followJob prints a job's logs as it writes them, like `logs --follow`,
+// then its final state, and returns the exit error for that state, if any.
+func followJob(id string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	f := store.LogFilter{Limit: 200}
+	for {
+		// Check the state before reading so the last read sees every chunk.
+		j, err := st.GetJob(ctx, id)
+		if ctx.Err() != nil { return waitError(ctx.Err()) }
+		if err != nil { return waitError(fmt.Errorf("job %s: %w", id, err)) }
+		if err := printLogs(ctx, id, &f, true); err != nil { return waitError(err) }
+		if j.State.Terminal() {
+			printJobEnd(j)
+			if code := jobExitCode(j.State); code != 0 { return &exitError{code: code} }
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return waitError(ctx.Err())
+		case <-time.After(waitPoll):
+		}
+	}
+}
+
+// waitError maps an error from waitFor to wait's exit codes.
+func waitError(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return &exitError{code: exitTimeout, err: fmt.Errorf("timed out after %s", waitTimeout)}
+	case errors.Is(err, context.Canceled):
+		return &exitError{code: exitInterrupted}
+	}
+	return &exitError{code: 1, err: err}
+}
+
+func jobExitCode(s models.JobState) int {
+	switch s {
+	case models.StateCompleted:
+		return 0
+	case models.StateCancelled:
+		return exitCancelled
+	}
+	return exitDead
+}
+
+func printJobEnd(j *models.Job) {
+	if j.Error == "" {
+		fmt.Printf("%s  %s\n", j.ID, j.State)
+		return
+	}
+	fmt.Printf("%s  %s  err=%q\n", j.ID, j.State, j.Error)
+}
+
+func init() {
+	waitCmd.Flags().DurationVar(&waitTimeout, "timeout", 0, "Give up after this long (default: wait forever)")
+	waitCmd.Flags().BoolVar(&waitLogs, "logs", false, "Print each job's logs when it finishes")
+	waitCmd.Flags().DurationVar(&waitPoll, "poll", 500*time.Millisecond, "How often to check the jobs' state")
+	rootCmd.AddCommand(waitCmd)
+}
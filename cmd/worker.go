@@ -2,14 +2,20 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os/exec"
+	"io"
+	"io/fs"
+	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/shashidhxr/queueCTL/internal/config"
 	"github.com/shashidhxr/queueCTL/internal/core"
+	"github.com/shashidhxr/queueCTL/internal/store"
 	"github.com/shashidhxr/queueCTL/pkg/models"
 	"github.com/spf13/cobra"
 )
@@ -27,9 +33,18 @@ var (
 	}
 
 	workerCount    int
-	backoffBase    float64
+	backoffBase    float64 // read through cfg (backoff_base)
 	pollInterval   time.Duration
+	maxPoll        time.Duration
+	workerBatch    int
 	printNoJobLogs bool
+	runScheduler   bool
+	workerQueues   string
+	heartbeatEvery time.Duration
+	leaseTTL       time.Duration
+	killGrace      time.Duration
+
+	workerHost string // host:pid, recorded with each attempt
 )
 
 func init() {
@@ -37,9 +52,16 @@ func init() {
 	rootCmd.AddCommand(workerCmd)
 
 	workerStartCmd.Flags().IntVar(&workerCount, "count", 1, "Number of worker goroutines")
-	workerStartCmd.Flags().Float64Var(&backoffBase, "backoff-base", 2.0, "Exponential backoff base (e.g., 2 => 2^attempts seconds)")
-	workerStartCmd.Flags().DurationVar(&pollInterval, "poll", 300*time.Millisecond, "Polling interval for new jobs")
+	workerStartCmd.Flags().Float64Var(&backoffBase, "backoff-base", 0, "Exponential backoff base, e.g. 2 => 2^attempts seconds (default: config backoff_base)")
+	workerStartCmd.Flags().DurationVar(&pollInterval, "poll", 300*time.Millisecond, "Polling interval for new jobs; doubles up to --max-poll while the queues stay empty")
+	workerStartCmd.Flags().DurationVar(&maxPoll, "max-poll", 2*time.Second, "Longest polling interval when idle (new jobs wake workers immediately; polling picks up retries coming due)")
+	workerStartCmd.Flags().IntVar(&workerBatch, "batch", 32, "Most jobs leased in one transaction (never more than the idle goroutines)")
 	workerStartCmd.Flags().BoolVar(&printNoJobLogs, "quiet-empty", true, "Suppress logs when no job is available")
+	workerStartCmd.Flags().StringVar(&workerQueues, "queues", "", "Comma-separated queues to consume, with optional weights (e.g. emails:3,reports:1); default all")
+	workerStartCmd.Flags().DurationVar(&heartbeatEvery, "heartbeat", 5*time.Second, "How often a running job's lease is renewed")
+	workerStartCmd.Flags().DurationVar(&leaseTTL, "lease", 30*time.Second, "Lease length granted by each heartbeat; a job is requeued if its worker stops renewing for this long")
+	workerStartCmd.Flags().DurationVar(&killGrace, "kill-grace", 10*time.Second, "Time between SIGTERM and SIGKILL when a job times out or is cancelled")
+	workerStartCmd.Flags().BoolVar(&runScheduler, "scheduler", true, "Also materialize jobs from recurring schedules")
 }
 
 func runWorkers(cmd *cobra.Command, args []string) error {
@@ -49,18 +71,53 @@ func runWorkers(cmd *cobra.Command, args []string) error {
 	if pollInterval <= 0 {
 		pollInterval = 300 * time.Millisecond
 	}
-	if backoffBase <= 0 {
-		backoffBase = 2.0
+	if maxPoll < pollInterval {
+		maxPoll = pollInterval
+	}
+	if workerBatch < 1 {
+		workerBatch = 1
+	}
+
+	if heartbeatEvery <= 0 || leaseTTL < 2*heartbeatEvery {
+		return fmt.Errorf("--lease (%s) must be at least twice --heartbeat (%s)", leaseTTL, heartbeatEvery)
 	}
 
+	queues, err := core.ParseQueueWeights(workerQueues)
+	if err != nil {
+		return err
+	}
+
+	// An older binary could write rows the newer schema doesn't expect.
+	if m, ok := st.(store.Migrator); ok {
+		cur, latest, err := m.SchemaVersion(context.Background())
+		if err != nil {
+			return err
+		}
+		if cur > latest {
+			return fmt.Errorf("%w (version %d, this binary knows up to %d); upgrade queuectl before starting workers", store.ErrSchemaTooNew, cur, latest)
+		}
+	}
+
+	host, _ := os.Hostname()
+	workerHost = fmt.Sprintf("%s:%d", host, os.Getpid())
+
 	// Graceful shutdown on Ctrl+C / SIGTERM
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	rm := core.NewRetryManager(*st) // uses default base internally
+	// Validate the effective config up front; it is re-resolved as it changes.
+	c, err := cfg.Resolve(context.Background())
+	if err != nil {
+		return err
+	}
+	rm := core.NewRetryManager(st, cfg)
 
-	fmt.Printf("Worker started: %d goroutine(s). Poll=%s, Backoff base=%.2f. Ctrl+C to stop…\n",
-		workerCount, pollInterval, backoffBase)
+	subscribed := "all"
+	if q := queues.Queues(); len(q) > 0 {
+		subscribed = strings.Join(q, ",")
+	}
+	fmt.Printf("Worker started: %d goroutine(s). Queues=%s, Poll=%s..%s, Backoff=%s (base %.2f, %s..%s). Ctrl+C to stop…\n",
+		workerCount, subscribed, pollInterval, maxPoll, c.BackoffStrategy, c.BackoffBase, c.BackoffMin, c.BackoffMax)
 
 	// Reaper: periodically requeue expired leases
 	go func() {
@@ -78,106 +135,364 @@ func runWorkers(cmd *cobra.Command, args []string) error {
 		}
 	}()
 
+	// Scheduler: materialize due recurring jobs; safe to run in every process
+	if runScheduler {
+		go func() {
+			sched := core.NewScheduler(st, cfg)
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case now := <-ticker.C:
+					n, err := sched.Tick(context.Background(), now)
+					if err != nil {
+						fmt.Println("scheduler:", err)
+					}
+					if n > 0 {
+						fmt.Printf("scheduler: created %d job(s)\n", n)
+					}
+				}
+			}
+		}()
+	}
+
 	var wg sync.WaitGroup
 	wg.Add(workerCount)
 
+	jobs := make(chan models.Job)
+	free := make(chan struct{}, workerCount)
 	for i := 0; i < workerCount; i++ {
+		free <- struct{}{}
 		go func(id int) {
 			defer wg.Done()
-			workerLoop(ctx, id, rm)
+			workerLoop(ctx, id, rm, jobs, free)
 		}(i + 1)
 	}
+	go dispatch(ctx, queues, jobs, free)
 
 	<-ctx.Done()
 	fmt.Println("\nShutting down workers gracefully…")
 	wg.Wait()
-	return nil
+	return st.Close()
 }
 
-func workerLoop(ctx context.Context, wid int, rm *core.RetryManager) {
-	ticker := time.NewTicker(pollInterval)
-	defer ticker.Stop()
+// acquireBatch leases up to n jobs, taking from the subscribed queues in
+// selector order. Jobs leased before an error are returned with it.
+func acquireBatch(ctx context.Context, queues *core.QueueSelector, n int) ([]models.Job, error) {
+	var out []models.Job
+	for _, q := range queues.Order() {
+		jobs, err := st.AcquireJobs(ctx, q, n-len(out))
+		out = append(out, jobs...)
+		if err != nil || len(out) == n {
+			return out, err
+		}
+	}
+	return out, nil
+}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
+// nextPoll is how long the dispatcher waits after finding nothing, having
+// waited idle before: the interval doubles from --poll up to --max-poll.
+func nextPoll(idle time.Duration) time.Duration {
+	if idle < pollInterval {
+		return pollInterval
+	}
+	return min(2*idle, maxPoll)
+}
 
-		case <-ticker.C:
-			// 1) Try to atomically claim a job
-			job, err := st.AcquireJob(ctx)
-			if err != nil {
-				fmt.Printf("[worker %d] acquire error: %v\n", wid, err)
-				continue
+// dispatch leases jobs for idle worker goroutines, one transaction per batch,
+// and hands them over on jobs. free receives a token whenever a goroutine
+// becomes idle; a batch is never larger than the goroutines waiting for it,
+// so no leased job sits in a buffer while its lease runs down. When the
+// queues are empty dispatch waits for the store to report a new job (see
+// store.Waker) or for its backed-off poll interval.
+func dispatch(ctx context.Context, queues *core.QueueSelector, jobs chan<- models.Job, free <-chan struct{}) {
+	defer close(jobs)
+	var wake <-chan struct{}
+	if w, ok := st.(store.Waker); ok {
+		wake = w.JobsAvailable()
+	}
+	var idle time.Duration
+	ready := 0
+
+	for {
+		if ready == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-free:
+				ready++
 			}
-			if job == nil {
-				if !printNoJobLogs {
-					fmt.Printf("[worker %d] no job available\n", wid)
-				}
-				continue
+		}
+		if idle > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(idle):
+			case <-wake:
+			}
+		}
+		for drained := false; !drained; {
+			select {
+			case <-free:
+				ready++
+			default:
+				drained = true
 			}
+		}
 
-			// 2) Execute the job command with timeout
-			effectiveTimeout := time.Duration(job.TimeoutSeconds) * time.Second
-			if effectiveTimeout <= 0 {
-				effectiveTimeout = 30 * time.Second // default
+		batch, err := acquireBatch(ctx, queues, min(ready, workerBatch))
+		if err != nil {
+			if ctx.Err() != nil && len(batch) == 0 {
+				return // shutting down
 			}
+			fmt.Printf("[dispatcher] acquire error: %v\n", err)
+		}
+		if len(batch) == 0 {
+			if err == nil && !printNoJobLogs {
+				fmt.Println("[dispatcher] no job available")
+			}
+			idle = nextPoll(idle)
+			continue
+		}
+		idle = 0 // look again as soon as a goroutine is free
+		for _, j := range batch {
+			jobs <- j // a goroutine is already waiting for it
+			ready--
+		}
+	}
+}
 
-			fmt.Printf("[worker %d] processing: %s (id=%s, timeout=%s)\n", wid, job.Command, job.ID, effectiveTimeout)
+// workerLoop runs the jobs it receives until jobs is closed, reporting on
+// free each time it is ready for another.
+func workerLoop(ctx context.Context, wid int, rm *core.RetryManager, jobs <-chan models.Job, free chan<- struct{}) {
+	for job := range jobs {
+		runJob(ctx, wid, rm, &job)
+		free <- struct{}{}
+	}
+}
 
-			execCtx, cancel := context.WithTimeout(ctx, effectiveTimeout)
-			out, execErr := exec.CommandContext(execCtx, "sh", "-c", job.Command).CombinedOutput()
-			cancel()
+// runJob executes one leased job and records its outcome.
+func runJob(ctx context.Context, wid int, rm *core.RetryManager, job *models.Job) {
+	// 1) Execute the job command with timeout
+	effectiveTimeout := time.Duration(job.TimeoutSeconds) * time.Second
+	if effectiveTimeout <= 0 {
+		effectiveTimeout = 30 * time.Second // default
+	}
 
-			// record logs
-			if len(out) > 0 {
-				fmt.Print(string(out))
-				_ = st.AppendLog(ctx, job.ID, "combined", string(out))
-			}
-			if execErr != nil && execCtx.Err() == context.DeadlineExceeded {
-				execErr = fmt.Errorf("timeout after %s", effectiveTimeout)
-			}
+	fmt.Printf("[worker %d] processing: %s (id=%s, queue=%s, timeout=%s)\n", wid, job.Command, job.ID, job.Queue, effectiveTimeout)
 
-			// 3) Update state based on result
-			if execErr == nil {
-				if err := st.SetCompleted(ctx, job.ID); err != nil {
-					fmt.Printf("[worker %d] update completed error: %v\n", wid, err)
-				} else {
-					fmt.Printf("[worker %d] completed: id=%s\n", wid, job.ID)
-				}
-				continue
-			}
+	attemptID, attemptNum, err := st.StartAttempt(ctx, job.ID, *job.WorkerID, fmt.Sprintf("%s/%d", workerHost, wid))
+	if err != nil {
+		fmt.Printf("[worker %d] record attempt error: %v\n", wid, err)
+	}
 
-			// failure path → exponential backoff (delay = base^attempts seconds)
-			delay := calculateBackoffWithBase(backoffBase, job.Attempts+1, rm)
-			if err := st.FailOrScheduleBackoff(ctx, job, delay, execErr.Error()); err != nil {
-				fmt.Printf("[worker %d] update retry/dead error: %v\n", wid, err)
-				continue
-			}
-			if job.Attempts+1 > job.MaxRetries {
-				fmt.Printf("[worker %d] job moved to DLQ (dead): id=%s err=%v\n", wid, job.ID, execErr)
-			} else {
-				fmt.Printf("[worker %d] failed (attempt %d/%d). next in %s. id=%s err=%v\n",
-					wid, job.Attempts+1, job.MaxRetries, delay, job.ID, execErr)
-			}
+	// The cause of execCtx tells a timeout from a lost lease or a cancel request.
+	runCtx, abort := context.WithCancelCause(ctx)
+	execCtx, cancel := context.WithTimeoutCause(runCtx, effectiveTimeout, fmt.Errorf("timeout after %s", effectiveTimeout))
+	stopHeartbeat := heartbeat(ctx, wid, job, abort)
+	capture := captureOutput(wid, job, attemptNum)
+	resultPath, env := resultFile(wid)
+	usage, execErr := core.RunCommand(execCtx, job.Command, env, killGrace, capture.Writer("stdout"), capture.Writer("stderr"))
+	capture.Close()
+	stopHeartbeat()
+	cause := context.Cause(execCtx)
+	cancel()
+	abort(nil)
+
+	// A timeout, cancel or lost lease decides the outcome even if the command
+	// handled SIGTERM and exited 0.
+	if cause != nil {
+		execErr = cause
+	}
+	var result string
+	if execErr == nil {
+		result, execErr = readResult(resultPath, capture)
+	}
+	if resultPath != "" {
+		_ = os.Remove(resultPath)
+	}
+	if attemptID != 0 {
+		errStr := ""
+		if execErr != nil {
+			errStr = execErr.Error()
+		}
+		if err := st.FinishAttempt(context.Background(), attemptID, usage, errStr); err != nil {
+			fmt.Printf("[worker %d] record attempt error: %v\n", wid, err)
+		}
+	}
+
+	// 2) Update state based on result, unless the job now belongs to another worker
+	if errors.Is(execErr, store.ErrLeaseLost) {
+		fmt.Printf("[worker %d] lease lost, result discarded: id=%s\n", wid, job.ID)
+		return
+	}
+	if errors.Is(execErr, errCancelRequested) {
+		if err := st.SetCancelled(ctx, job.ID, *job.WorkerID); err != nil {
+			fmt.Printf("[worker %d] update cancelled error: %v\n", wid, err)
+		} else {
+			fmt.Printf("[worker %d] cancelled: id=%s\n", wid, job.ID)
 		}
+		return
+	}
+	if execErr == nil {
+		if err := st.SetCompleted(ctx, job.ID, *job.WorkerID, result); err != nil {
+			fmt.Printf("[worker %d] update completed error: %v\n", wid, err)
+		} else {
+			fmt.Printf("[worker %d] completed: id=%s\n", wid, job.ID)
+		}
+		return
+	}
+
+	// failure path → the retry policy decides between backoff and the DLQ
+	d := rm.Decide(ctx, job, core.Failure{
+		ExitCode:   usage.ExitCode,
+		Stderr:     capture.Tail("stderr"),
+		RetryAfter: capture.Directive("retry-after"),
+	})
+	if !d.Retry {
+		if err := st.SetDead(ctx, job, fmt.Sprintf("%v (not retried: %s)", execErr, d.Reason)); err != nil {
+			fmt.Printf("[worker %d] update dead error: %v\n", wid, err)
+		} else {
+			fmt.Printf("[worker %d] job moved to DLQ (dead), not retried: %s. id=%s err=%v\n", wid, d.Reason, job.ID, execErr)
+		}
+		return
+	}
+	if err := st.FailOrSchedule(ctx, job, d.Backoff, execErr.Error()); err != nil {
+		fmt.Printf("[worker %d] update retry/dead error: %v\n", wid, err)
+		return
+	}
+	// FailOrSchedule has already counted this attempt in job.Attempts and set
+	// the delay it picked
+	if job.Attempts > job.MaxRetries {
+		fmt.Printf("[worker %d] job moved to DLQ (dead): id=%s err=%v\n", wid, job.ID, execErr)
+	} else {
+		fmt.Printf("[worker %d] failed (attempt %d/%d). next in %s. id=%s err=%v\n",
+			wid, job.Attempts, job.MaxRetries, time.Duration(job.RetryDelayMS)*time.Millisecond, job.ID, execErr)
 	}
 }
 
-func calculateBackoffWithBase(base float64, attempts int, rm *core.RetryManager) time.Duration {
-	if base <= 0 {
-		return rm.CalculateBackoff(attempts)
+// captureOutput streams a job's stdout and stderr to job_logs (and to this
+// worker's own stdout/stderr) while it runs, up to the max_log_bytes config.
+// Lines are kept whole up to max_result_bytes, so a result directive can be as
+// long as a result file.
+func captureOutput(wid int, job *models.Job, attempt int) *core.OutputCapture {
+	c := config.Defaults()
+	if rc, err := cfg.Resolve(context.Background()); err == nil {
+		c = rc
 	}
-	// inline calculation with cap at 5 minutes
-	seconds := 1.0
-	for i := 0; i < attempts; i++ {
-		seconds *= base
+	capture := core.NewOutputCapture(c.MaxLogBytes, 500*time.Millisecond, func(stream, chunk string) {
+		if stream == "stderr" {
+			fmt.Fprint(os.Stderr, chunk)
+		} else {
+			fmt.Print(chunk)
+		}
+		if err := st.AppendLog(context.Background(), job.ID, attempt, stream, chunk); err != nil {
+			fmt.Printf("[worker %d] log error: %v\n", wid, err)
+		}
+	})
+	if c.MaxResultBytes > 0 {
+		capture.MaxLine = max(capture.MaxLine, int(c.MaxResultBytes)+len("##queuectl result \n"))
+	} else {
+		capture.MaxLine = 0
 	}
-	if seconds > 300.0 {
-		seconds = 300.0
+	return capture
+}
+
+// resultFile creates the file a job may write its result to and returns its
+// path with the environment that passes it on as QUEUECTL_RESULT_FILE. If the
+// file can't be created the job still runs, with only the stdout directive.
+func resultFile(wid int) (path string, env []string) {
+	f, err := os.CreateTemp("", "queuectl-result-*")
+	if err != nil {
+		fmt.Printf("[worker %d] result file error: %v\n", wid, err)
+		return "", nil
 	}
-	return time.Duration(seconds) * time.Second
+	f.Close()
+	return f.Name(), []string{"QUEUECTL_RESULT_FILE=" + f.Name()}
 }
 
-// Keep models import considered used if needed
-var _ = models.Job{}
+// readResult returns what a successful job reported: the contents of its
+// result file if it wrote any, otherwise the value of the last
+// "##queuectl result <value>" line it printed. A result over max_result_bytes
+// fails the attempt rather than being stored cut short.
+func readResult(path string, capture *core.OutputCapture) (string, error) {
+	limit := config.Defaults().MaxResultBytes
+	if c, err := cfg.Resolve(context.Background()); err == nil {
+		limit = c.MaxResultBytes
+	}
+	var result string
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("read result: %w", err)
+		}
+		if err == nil {
+			defer f.Close()
+			var r io.Reader = f
+			if limit > 0 {
+				r = io.LimitReader(f, limit+1)
+			}
+			b, err := io.ReadAll(r)
+			if err != nil {
+				return "", fmt.Errorf("read result: %w", err)
+			}
+			result = string(b)
+		}
+	}
+	if result == "" {
+		if capture.DirectiveCut("result") {
+			return "", fmt.Errorf("result exceeds max_result_bytes (%d)", limit)
+		}
+		result = capture.Directive("result")
+	}
+	if limit > 0 && int64(len(result)) > limit {
+		return "", fmt.Errorf("result exceeds max_result_bytes (%d)", limit)
+	}
+	return result, nil
+}
+
+var errCancelRequested = errors.New("cancelled")
+
+// heartbeat renews job's lease every --heartbeat until the returned stop
+// function is called. abort is called to stop the command with
+// store.ErrLeaseLost when the lease turns out to be lost (the reaper requeued
+// the job, e.g. after this process was paused; the result must then be
+// discarded), or with errCancelRequested when `queuectl cancel` flagged the job.
+func heartbeat(ctx context.Context, wid int, job *models.Job, abort context.CancelCauseFunc) (stop func()) {
+	hbCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(heartbeatEvery)
+		defer ticker.Stop()
+		cancelSeen := false
+		for {
+			select {
+			case <-hbCtx.Done():
+				return
+			case <-ticker.C:
+				cancelRequested, err := st.RenewLease(hbCtx, job.ID, *job.WorkerID, time.Now().Add(leaseTTL))
+				if errors.Is(err, store.ErrLeaseLost) {
+					fmt.Printf("[worker %d] lease lost while running: id=%s\n", wid, job.ID)
+					abort(store.ErrLeaseLost)
+					return
+				}
+				if err != nil && hbCtx.Err() == nil {
+					fmt.Printf("[worker %d] heartbeat error: %v\n", wid, err)
+				}
+				// keep renewing while the command shuts down
+				if cancelRequested && !cancelSeen {
+					cancelSeen = true
+					fmt.Printf("[worker %d] cancel requested, stopping: id=%s\n", wid, job.ID)
+					abort(errCancelRequested)
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
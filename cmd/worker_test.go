@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shashidhxr/queueCTL/internal/config"
+	"github.com/shashidhxr/queueCTL/internal/core"
+	"github.com/shashidhxr/queueCTL/internal/store"
+	"github.com/shashidhxr/queueCTL/pkg/models"
+)
+
+// trapsTerm exits 0 on SIGTERM, as a job that shuts down cleanly would.
+const trapsTerm = `trap 'echo got term; exit 0' TERM; sleep 10 & wait`
+
+// useTestStore points the worker at a fresh in-memory store, with heartbeats
+// fast enough to notice a cancel within the test.
+func useTestStore(t *testing.T) {
+	prevSt, prevCfg, prevHB, prevLease, prevGrace := st, cfg, heartbeatEvery, leaseTTL, killGrace
+	t.Cleanup(func() { st, cfg, heartbeatEvery, leaseTTL, killGrace = prevSt, prevCfg, prevHB, prevLease, prevGrace })
+	st = store.NewMemoryStorage()
+	cfg = config.NewResolver(st)
+	heartbeatEvery, leaseTTL, killGrace = 50*time.Millisecond, time.Second, 5*time.Second
+}
+
+// leaseTestJob saves a job running command and leases it.
+func leaseTestJob(t *testing.T, command string, timeout int) *models.Job {
+	t.Helper()
+	ctx := context.Background()
+	if err := st.SaveJob(ctx, &models.Job{ID: "job-1", Command: command, MaxRetries: 3, TimeoutSeconds: timeout}); err != nil {
+		t.Fatal(err)
+	}
+	j, err := st.AcquireJobByID(ctx, "job-1")
+	if err != nil || j == nil {
+		t.Fatalf("AcquireJobByID() = %v, %v", j, err)
+	}
+	return j
+}
+
+func TestRunJobCancelledJobThatExitsZeroIsCancelled(t *testing.T) {
+	useTestStore(t)
+	ctx := context.Background()
+	j := leaseTestJob(t, trapsTerm, 30)
+	if _, err := st.CancelJob(ctx, j.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	runJob(ctx, 1, core.NewRetryManager(st, cfg), j)
+	got, err := st.GetJob(ctx, j.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.State != models.StateCancelled {
+		t.Errorf("state = %s, want %s", got.State, models.StateCancelled)
+	}
+}
+
+func TestRunJobTimedOutJobThatExitsZeroFails(t *testing.T) {
+	useTestStore(t)
+	ctx := context.Background()
+	j := leaseTestJob(t, trapsTerm, 1)
+
+	runJob(ctx, 1, core.NewRetryManager(st, cfg), j)
+	got, err := st.GetJob(ctx, j.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.State != models.StatePending || got.Attempts != 1 || got.Error != "timeout after 1s" {
+		t.Errorf("job = %s, attempts %d, error %q; want pending for a retry after a timeout", got.State, got.Attempts, got.Error)
+	}
+}
+
+func TestRunJobKeepsResultDirectiveOverLineBuffer(t *testing.T) {
+	const printResult = `printf '##queuectl result %s\n' "$(head -c 6000 /dev/zero | tr '\0' x)"`
+	tests := []struct {
+		maxResult string
+		want      models.JobState
+	}{
+		{"65536", models.StateCompleted},
+		{"5000", models.StatePending}, // failed, to be retried
+	}
+	for _, tt := range tests {
+		t.Run(tt.maxResult, func(t *testing.T) {
+			useTestStore(t)
+			ctx := context.Background()
+			if err := st.SetConfig(ctx, "max_result_bytes", tt.maxResult); err != nil {
+				t.Fatal(err)
+			}
+			j := leaseTestJob(t, printResult, 30)
+
+			runJob(ctx, 1, core.NewRetryManager(st, cfg), j)
+			got, err := st.GetJob(ctx, j.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.State != tt.want {
+				t.Fatalf("state = %s (error %q), want %s", got.State, got.Error, tt.want)
+			}
+			if got.State == models.StateCompleted && len(got.Result) != 6000 {
+				t.Errorf("result = %d bytes, want 6000", len(got.Result))
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ go 1.22.3
 require (
 	github.com/inconshreveable/mousetrap v1.1.0
 	github.com/google/uuid v1.6.0
+	github.com/lib/pq v1.10.9
 	github.com/mattn/go-sqlite3 v1.14.32
 	github.com/spf13/cobra v1.10.1
 	github.com/spf13/pflag v1.0.10
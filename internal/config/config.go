@@ -0,0 +1,220 @@
+// Package config resolves queueCTL settings from, in order of precedence,
+// command-line flags, QUEUECTL_* environment variables, the config table in
+// the database, and built-in defaults.
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shashidhxr/queueCTL/pkg/models"
+	"github.com/spf13/pflag"
+)
+
+// Key describes one known configuration setting.
+type Key struct {
+	Name    string
+	Default string
+	Help    string
+	parse   func(v string, cfg *models.Config) error
+}
+
+// Env is the environment variable that overrides the key, e.g. QUEUECTL_MAX_RETRIES.
+func (k Key) Env() string { return "QUEUECTL_" + strings.ToUpper(k.Name) }
+
+var keys = map[string]Key{}
+
+func register(k Key) { keys[k.Name] = k }
+
+func init() {
+	register(Key{
+		Name: "max_retries", Default: "3", Help: "retries before a job moves to the DLQ",
+		parse: func(v string, cfg *models.Config) error {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 { return fmt.Errorf("must be a non-negative integer") }
+			cfg.MaxRetries = n
+			return nil
+		},
+	})
+	register(Key{
+		Name: "backoff_base", Default: "2", Help: "growth factor of exponential backoff (delay = backoff_min * base^attempts)",
+		parse: func(v string, cfg *models.Config) error {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil || f < 1 { return fmt.Errorf("must be a number >= 1") }
+			cfg.BackoffBase = f
+			return nil
+		},
+	})
+	register(Key{
+		Name: "backoff_strategy", Default: models.BackoffExponential,
+		Help: "retry delay strategy: fixed|linear|exponential|decorrelated-jitter|full-jitter",
+		parse: func(v string, cfg *models.Config) error {
+			switch v {
+			case models.BackoffFixed, models.BackoffLinear, models.BackoffExponential,
+				models.BackoffDecorrelatedJitter, models.BackoffFullJitter:
+			default:
+				return fmt.Errorf("must be fixed, linear, exponential, decorrelated-jitter or full-jitter")
+			}
+			cfg.BackoffStrategy = v
+			return nil
+		},
+	})
+	register(Key{
+		Name: "backoff_min", Default: "1s", Help: "shortest retry delay; also the fixed delay, linear step and exponential unit",
+		parse: func(v string, cfg *models.Config) error {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < time.Millisecond { return fmt.Errorf("must be a duration of at least 1ms") }
+			cfg.BackoffMin = d
+			return nil
+		},
+	})
+	register(Key{
+		Name: "backoff_max", Default: "5m", Help: "longest retry delay (0 = no cap)",
+		parse: func(v string, cfg *models.Config) error {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 { return fmt.Errorf("must be a non-negative duration") }
+			cfg.BackoffMax = d
+			return nil
+		},
+	})
+	register(Key{
+		Name: "max_log_bytes", Default: "1048576", Help: "output kept per job attempt before truncation (0 = unlimited)",
+		parse: func(v string, cfg *models.Config) error {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 { return fmt.Errorf("must be a non-negative integer") }
+			cfg.MaxLogBytes = n
+			return nil
+		},
+	})
+	register(Key{
+		Name: "max_result_bytes", Default: "65536", Help: "largest result a job may report; a larger one fails the attempt (0 = unlimited)",
+		parse: func(v string, cfg *models.Config) error {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 { return fmt.Errorf("must be a non-negative integer") }
+			cfg.MaxResultBytes = n
+			return nil
+		},
+	})
+	register(Key{
+		Name: "job_timeout", Default: "30s", Help: "default per-job timeout for enqueue",
+		parse: func(v string, cfg *models.Config) error {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < time.Second { return fmt.Errorf("must be a duration of at least 1s") }
+			cfg.JobTimeout = d
+			return nil
+		},
+	})
+}
+
+// Keys returns every known key sorted by name.
+func Keys() []Key {
+	out := make([]Key, 0, len(keys))
+	for _, k := range keys { out = append(out, k) }
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Validate reports whether value is acceptable for key.
+func Validate(key, value string) error {
+	k, ok := keys[key]
+	if !ok { return fmt.Errorf("unknown config key %q", key) }
+	if err := k.parse(value, &models.Config{}); err != nil {
+		return fmt.Errorf("invalid %s %q: %w", key, value, err)
+	}
+	return nil
+}
+
+// Source provides the values stored in the database.
+type Source interface {
+	GetConfigValues(ctx context.Context) (map[string]string, error)
+}
+
+// Value is a resolved setting and where it came from.
+type Value struct {
+	Key    string
+	Value  string
+	Source string // flag, env, db or default
+}
+
+// Resolver merges all configuration sources. Database values are cached for
+// TTL, so long-running workers pick up `config set` changes without restart.
+type Resolver struct {
+	src   Source
+	flags map[string]*pflag.Flag
+	TTL   time.Duration
+
+	mu       sync.Mutex
+	db       map[string]string
+	loadedAt time.Time
+}
+
+func NewResolver(src Source) *Resolver {
+	return &Resolver{src: src, flags: map[string]*pflag.Flag{}, TTL: 5 * time.Second}
+}
+
+// BindFlags lets flags in fs override the key of the same name (with "-" for
+// "_", e.g. --max-retries for max_retries) when they are set explicitly.
+func (r *Resolver) BindFlags(fs *pflag.FlagSet) {
+	for name := range keys {
+		if f := fs.Lookup(strings.ReplaceAll(name, "_", "-")); f != nil {
+			r.flags[name] = f
+		}
+	}
+}
+
+func (r *Resolver) dbValues(ctx context.Context) (map[string]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.db != nil && time.Since(r.loadedAt) < r.TTL {
+		return r.db, nil
+	}
+	m, err := r.src.GetConfigValues(ctx)
+	if err != nil { return nil, err }
+	r.db, r.loadedAt = m, time.Now()
+	return m, nil
+}
+
+// Values returns every known key with its effective value and source.
+func (r *Resolver) Values(ctx context.Context) ([]Value, error) {
+	db, err := r.dbValues(ctx)
+	if err != nil { return nil, err }
+	var out []Value
+	for _, k := range Keys() {
+		v := Value{Key: k.Name, Value: k.Default, Source: "default"}
+		if f, ok := r.flags[k.Name]; ok && f.Changed {
+			v.Value, v.Source = f.Value.String(), "flag"
+		} else if env, ok := os.LookupEnv(k.Env()); ok {
+			v.Value, v.Source = env, "env"
+		} else if d, ok := db[k.Name]; ok {
+			v.Value, v.Source = d, "db"
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// Resolve returns the effective configuration.
+func (r *Resolver) Resolve(ctx context.Context) (*models.Config, error) {
+	vals, err := r.Values(ctx)
+	if err != nil { return nil, err }
+	cfg := &models.Config{}
+	for _, v := range vals {
+		if err := keys[v.Key].parse(v.Value, cfg); err != nil {
+			return nil, fmt.Errorf("invalid %s %q from %s: %w", v.Key, v.Value, v.Source, err)
+		}
+	}
+	return cfg, nil
+}
+
+// Defaults returns the configuration made only of built-in defaults.
+func Defaults() *models.Config {
+	cfg := &models.Config{}
+	for _, k := range keys { _ = k.parse(k.Default, cfg) }
+	return cfg
+}
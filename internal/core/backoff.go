@@ -0,0 +1,129 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/shashidhxr/queueCTL/pkg/models"
+)
+
+// BackoffStrategy computes the delay before a retry. attempt is the number of
+// the failed attempt (1 for the first failure) and prev the delay used before
+// it, 0 if there was none.
+type BackoffStrategy interface {
+	Next(attempt int, prev time.Duration) time.Duration
+}
+
+// FixedBackoff always waits Delay.
+type FixedBackoff struct{ Delay time.Duration }
+
+func (b FixedBackoff) Next(int, time.Duration) time.Duration { return b.Delay }
+
+// LinearBackoff waits Step, 2*Step, 3*Step, ...
+type LinearBackoff struct{ Step time.Duration }
+
+func (b LinearBackoff) Next(attempt int, _ time.Duration) time.Duration {
+	return time.Duration(attempt) * b.Step
+}
+
+// ExponentialBackoff waits Unit*Base^attempt.
+type ExponentialBackoff struct {
+	Base float64
+	Unit time.Duration
+}
+
+func (b ExponentialBackoff) Next(attempt int, _ time.Duration) time.Duration {
+	return scaleDuration(b.Unit, math.Pow(b.Base, float64(attempt)))
+}
+
+// FullJitterBackoff waits a random delay between Unit and the exponential
+// delay, so jobs that failed together don't all come back together.
+type FullJitterBackoff struct {
+	Base float64
+	Unit time.Duration
+}
+
+func (b FullJitterBackoff) Next(attempt int, prev time.Duration) time.Duration {
+	return randBetween(b.Unit, ExponentialBackoff(b).Next(attempt, prev))
+}
+
+// DecorrelatedJitterBackoff waits a random delay between Unit and three times
+// the previous delay, which spreads retries while still growing.
+type DecorrelatedJitterBackoff struct{ Unit time.Duration }
+
+func (b DecorrelatedJitterBackoff) Next(_ int, prev time.Duration) time.Duration {
+	if prev < b.Unit {
+		prev = b.Unit
+	}
+	return randBetween(b.Unit, 3*prev)
+}
+
+// BoundedBackoff clamps another strategy's delays to [Min, Max]; a zero Max
+// means no upper bound.
+type BoundedBackoff struct {
+	BackoffStrategy
+	Min, Max time.Duration
+}
+
+func (b BoundedBackoff) Next(attempt int, prev time.Duration) time.Duration {
+	d := b.BackoffStrategy.Next(attempt, prev)
+	if b.Max > 0 && d > b.Max {
+		d = b.Max
+	}
+	if d < b.Min {
+		d = b.Min
+	}
+	return d
+}
+
+// NewBackoffStrategy builds the strategy a policy describes. Min is the fixed
+// delay, the linear step and the unit of the others; every delay is clamped
+// to [Min, Max].
+func NewBackoffStrategy(p models.BackoffPolicy) (BackoffStrategy, error) {
+	min, max := time.Duration(p.MinMS)*time.Millisecond, time.Duration(p.MaxMS)*time.Millisecond
+	if min <= 0 {
+		return nil, fmt.Errorf("backoff min must be positive")
+	}
+	if max > 0 && max < min {
+		return nil, fmt.Errorf("backoff max (%s) is below min (%s)", max, min)
+	}
+	var s BackoffStrategy
+	switch p.Strategy {
+	case models.BackoffFixed:
+		s = FixedBackoff{Delay: min}
+	case models.BackoffLinear:
+		s = LinearBackoff{Step: min}
+	case models.BackoffExponential, models.BackoffFullJitter:
+		if p.Base < 1 {
+			return nil, fmt.Errorf("backoff base must be >= 1")
+		}
+		if p.Strategy == models.BackoffExponential {
+			s = ExponentialBackoff{Base: p.Base, Unit: min}
+		} else {
+			s = FullJitterBackoff{Base: p.Base, Unit: min}
+		}
+	case models.BackoffDecorrelatedJitter:
+		s = DecorrelatedJitterBackoff{Unit: min}
+	default:
+		return nil, fmt.Errorf("unknown backoff strategy %q (fixed|linear|exponential|decorrelated-jitter|full-jitter)", p.Strategy)
+	}
+	return BoundedBackoff{BackoffStrategy: s, Min: min, Max: max}, nil
+}
+
+// scaleDuration multiplies d by f without overflowing.
+func scaleDuration(d time.Duration, f float64) time.Duration {
+	v := float64(d) * f
+	if v >= math.MaxInt64 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return math.MaxInt64
+	}
+	return time.Duration(v)
+}
+
+func randBetween(lo, hi time.Duration) time.Duration {
+	if hi <= lo {
+		return lo
+	}
+	return lo + rand.N(hi-lo+1)
+}
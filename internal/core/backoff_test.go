@@ -0,0 +1,85 @@
+package core
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/shashidhxr/queueCTL/pkg/models"
+)
+
+func TestBackoffStrategies(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  models.BackoffPolicy
+		attempt int
+		prev    time.Duration
+		want    time.Duration
+	}{
+		{"fixed", models.BackoffPolicy{Strategy: models.BackoffFixed, MinMS: 500}, 4, 0, 500 * time.Millisecond},
+		{"linear", models.BackoffPolicy{Strategy: models.BackoffLinear, MinMS: 1000}, 3, 0, 3 * time.Second},
+		{"exponential", models.BackoffPolicy{Strategy: models.BackoffExponential, Base: 2, MinMS: 1000}, 3, 0, 8 * time.Second},
+		{"exponential base 1", models.BackoffPolicy{Strategy: models.BackoffExponential, Base: 1, MinMS: 250}, 10, 0, 250 * time.Millisecond},
+		{"capped by max", models.BackoffPolicy{Strategy: models.BackoffExponential, Base: 2, MinMS: 1000, MaxMS: 5000}, 10, 0, 5 * time.Second},
+		{"huge attempt doesn't overflow", models.BackoffPolicy{Strategy: models.BackoffExponential, Base: 10, MinMS: 1000}, 400, 0, math.MaxInt64},
+		{"linear capped", models.BackoffPolicy{Strategy: models.BackoffLinear, MinMS: 1000, MaxMS: 2500}, 5, 0, 2500 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewBackoffStrategy(tt.policy)
+			if err != nil {
+				t.Fatalf("NewBackoffStrategy: %v", err)
+			}
+			if got := s.Next(tt.attempt, tt.prev); got != tt.want {
+				t.Errorf("Next(%d, %s) = %s, want %s", tt.attempt, tt.prev, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJitterBackoffStaysInRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  models.BackoffPolicy
+		attempt int
+		prev    time.Duration
+		lo, hi  time.Duration
+	}{
+		{"full jitter", models.BackoffPolicy{Strategy: models.BackoffFullJitter, Base: 2, MinMS: 100}, 4, 0, 100 * time.Millisecond, 1600 * time.Millisecond},
+		{"decorrelated from nothing", models.BackoffPolicy{Strategy: models.BackoffDecorrelatedJitter, MinMS: 100}, 1, 0, 100 * time.Millisecond, 300 * time.Millisecond},
+		{"decorrelated grows from prev", models.BackoffPolicy{Strategy: models.BackoffDecorrelatedJitter, MinMS: 100}, 5, time.Second, 100 * time.Millisecond, 3 * time.Second},
+		{"decorrelated capped", models.BackoffPolicy{Strategy: models.BackoffDecorrelatedJitter, MinMS: 100, MaxMS: 500}, 5, time.Minute, 100 * time.Millisecond, 500 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewBackoffStrategy(tt.policy)
+			if err != nil {
+				t.Fatalf("NewBackoffStrategy: %v", err)
+			}
+			for i := 0; i < 1000; i++ {
+				if got := s.Next(tt.attempt, tt.prev); got < tt.lo || got > tt.hi {
+					t.Fatalf("Next(%d, %s) = %s, want within [%s, %s]", tt.attempt, tt.prev, got, tt.lo, tt.hi)
+				}
+			}
+		})
+	}
+}
+
+func TestNewBackoffStrategyRejectsInvalidPolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy models.BackoffPolicy
+	}{
+		{"no min", models.BackoffPolicy{Strategy: models.BackoffFixed}},
+		{"max below min", models.BackoffPolicy{Strategy: models.BackoffFixed, MinMS: 1000, MaxMS: 500}},
+		{"base below 1", models.BackoffPolicy{Strategy: models.BackoffExponential, Base: 0.5, MinMS: 1000}},
+		{"unknown strategy", models.BackoffPolicy{Strategy: "fibonacci", MinMS: 1000}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewBackoffStrategy(tt.policy); err == nil {
+				t.Errorf("NewBackoffStrategy(%+v) succeeded, want an error", tt.policy)
+			}
+		})
+	}
+}
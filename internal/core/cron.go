@@ -0,0 +1,151 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule yields the activation times of a recurring job.
+type Schedule interface {
+	// Next returns the first activation strictly after t.
+	Next(t time.Time) time.Time
+}
+
+// ParseSchedule accepts a standard five-field cron expression
+// ("min hour dom month dow"), one of the @hourly/@daily/@weekly/@monthly/@yearly
+// macros, or "@every <duration>". Cron expressions are evaluated in UTC.
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil { return nil, fmt.Errorf("invalid @every duration: %w", err) }
+		if d < time.Second { return nil, fmt.Errorf("@every interval must be at least 1s, got %s", d) }
+		return everySchedule{d: d.Truncate(time.Second)}, nil
+	}
+	switch spec {
+	case "@yearly", "@annually":
+		spec = "0 0 1 1 *"
+	case "@monthly":
+		spec = "0 0 1 * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	case "@daily", "@midnight":
+		spec = "0 0 * * *"
+	case "@hourly":
+		spec = "0 * * * *"
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields", spec)
+	}
+	var c cronSchedule
+	var err error
+	if c.minute, err = parseField(fields[0], 0, 59, nil); err != nil { return nil, fmt.Errorf("minute: %w", err) }
+	if c.hour, err = parseField(fields[1], 0, 23, nil); err != nil { return nil, fmt.Errorf("hour: %w", err) }
+	if c.dom, err = parseField(fields[2], 1, 31, nil); err != nil { return nil, fmt.Errorf("day of month: %w", err) }
+	if c.month, err = parseField(fields[3], 1, 12, monthNames); err != nil { return nil, fmt.Errorf("month: %w", err) }
+	if c.dow, err = parseField(fields[4], 0, 7, dowNames); err != nil { return nil, fmt.Errorf("day of week: %w", err) }
+	if c.dow&(1<<7) != 0 { c.dow |= 1 } // 7 is Sunday too
+	c.domStar = fields[2] == "*" || strings.HasPrefix(fields[2], "*/")
+	c.dowStar = fields[4] == "*" || strings.HasPrefix(fields[4], "*/")
+	return c, nil
+}
+
+type everySchedule struct{ d time.Duration }
+
+func (e everySchedule) Next(t time.Time) time.Time {
+	return t.Truncate(time.Second).Add(e.d)
+}
+
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+func (c cronSchedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{} // unsatisfiable, e.g. "0 0 30 2 *"
+}
+
+// dayMatches follows cron semantics: when both day-of-month and day-of-week
+// are restricted, either one matching is enough.
+func (c cronSchedule) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dowNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+func parseField(field string, min, max int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 { return 0, fmt.Errorf("invalid step in %q", part) }
+			rng, step = part[:i], n
+		}
+		lo, hi := min, max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if lo, err = parseValue(bounds[0], names); err != nil { return 0, err }
+			if hi, err = parseValue(bounds[1], names); err != nil { return 0, err }
+		default:
+			v, err := parseValue(rng, names)
+			if err != nil { return 0, err }
+			lo, hi = v, v
+			if step > 1 { hi = max } // "5/15" means 5, 20, 35, ...
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok { return v, nil }
+	v, err := strconv.Atoi(s)
+	if err != nil { return 0, fmt.Errorf("invalid value %q", s) }
+	return v, nil
+}
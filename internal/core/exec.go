@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/shashidhxr/queueCTL/pkg/models"
+)
+
+// RunCommand runs command through `sh -c` in its own process group, with env
+// added to the worker's environment, copying its stdout and stderr to the
+// given writers as it runs. When ctx is done the
+// whole group receives SIGTERM, and SIGKILL if it is still running after
+// grace, so timeouts and cancellation also reach processes the command spawned.
+// The returned usage has no exit code if the command could not be started.
+func RunCommand(ctx context.Context, command string, env []string, grace time.Duration, stdout, stderr io.Writer) (models.Usage, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	// Don't hang on a daemonized grandchild that keeps our pipes open.
+	cmd.WaitDelay = grace
+	setProcessGroup(cmd)
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return models.Usage{}, err
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		select {
+		case <-exited:
+		case <-ctx.Done():
+			signalGroup(cmd, syscall.SIGTERM)
+			select {
+			case <-exited:
+			case <-time.After(grace):
+				signalGroup(cmd, syscall.SIGKILL)
+			}
+		}
+	}()
+	err := cmd.Wait()
+	close(exited)
+	return usageOf(cmd.ProcessState, time.Since(start)), err
+}
+
+func usageOf(ps *os.ProcessState, wall time.Duration) models.Usage {
+	u := models.Usage{WallMS: wall.Milliseconds()}
+	if ps == nil {
+		return u
+	}
+	code := ps.ExitCode()
+	u.ExitCode = &code
+	u.UserMS = ps.UserTime().Milliseconds()
+	u.SysMS = ps.SystemTime().Milliseconds()
+	u.Signal, u.MaxRSSKB = processStats(ps)
+	return u
+}
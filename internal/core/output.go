@@ -0,0 +1,241 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OutputCapture streams a running job's output to a sink (normally
+// store.AppendLog) in bounded chunks instead of buffering it until exit.
+// Complete lines are batched and flushed every flush interval or as soon as
+// MaxChunk bytes are pending; a partial line is flushed by the timer too, so
+// progress output without newlines still shows up. Once the job has produced
+// limit bytes in total, further output is dropped and a truncation marker is
+// emitted instead.
+//
+// Independently of the limit, the capture keeps the last TailBytes of each
+// stream for the retry policy, and remembers directive lines of the form
+// "##queuectl <name> <value>" the job printed on either stream.
+type OutputCapture struct {
+	sink     func(stream, chunk string)
+	limit    int64
+	MaxChunk int
+	// MaxLine is the longest line kept whole for directives, 0 for no limit.
+	// Set it before the first write.
+	MaxLine int
+
+	mu         sync.Mutex
+	streams    []*streamWriter
+	written    int64
+	truncated  bool
+	directives map[string]string
+	cut        map[string]bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewOutputCapture starts a capture; limit <= 0 means unlimited. Close must be
+// called once the command has exited.
+func NewOutputCapture(limit int64, flushEvery time.Duration, sink func(stream, chunk string)) *OutputCapture {
+	c := &OutputCapture{
+		sink:     sink,
+		limit:    limit,
+		MaxChunk: 4096,
+		MaxLine:  4096,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+
+		directives: map[string]string{},
+		cut:        map[string]bool{},
+	}
+	go func() {
+		defer close(c.done)
+		ticker := time.NewTicker(flushEvery)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+				c.flushAll()
+			}
+		}
+	}()
+	return c
+}
+
+// Writer returns the writer for one named stream, e.g. "stdout".
+func (c *OutputCapture) Writer(stream string) io.Writer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &streamWriter{c: c, name: stream}
+	c.streams = append(c.streams, w)
+	return w
+}
+
+// Tail returns the last TailBytes a stream produced, including output dropped
+// by the limit.
+func (c *OutputCapture) Tail(stream string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, w := range c.streams {
+		if w.name == stream {
+			return string(w.tail)
+		}
+	}
+	return ""
+}
+
+// Directive returns the value of the last "##queuectl <name> <value>" line
+// the job printed, or "" if it printed none.
+func (c *OutputCapture) Directive(name string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.directives[name]
+}
+
+// DirectiveCut reports whether the last "##queuectl <name>" line was longer
+// than MaxLine, so Directive returns only its start.
+func (c *OutputCapture) DirectiveCut(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cut[name]
+}
+
+// Truncated reports whether output was dropped because of the limit.
+func (c *OutputCapture) Truncated() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.truncated
+}
+
+// Close stops the flush timer and flushes whatever is still pending.
+func (c *OutputCapture) Close() {
+	close(c.stop)
+	<-c.done
+	c.mu.Lock()
+	for _, w := range c.streams {
+		w.scanLine() // a final line without a trailing newline
+	}
+	c.mu.Unlock()
+	c.flushAll()
+}
+
+func (c *OutputCapture) flushAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, w := range c.streams {
+		w.flush(0)
+	}
+}
+
+const (
+	// TailBytes is how much of the end of each stream Tail keeps.
+	TailBytes = 64 << 10
+
+	directivePrefix = "##queuectl "
+)
+
+type streamWriter struct {
+	c    *OutputCapture
+	name string
+	buf  []byte
+	tail []byte
+	line []byte
+	// lineCut is set when the current line outgrew MaxLine.
+	lineCut bool
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	c := w.c
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := len(p)
+	w.keepTail(p)
+	w.scan(p)
+	if c.truncated {
+		return n, nil // keep draining the pipe so the job doesn't block
+	}
+	if c.limit > 0 && c.written+int64(len(p)) > c.limit {
+		p = p[:c.limit-c.written]
+		c.truncated = true
+	}
+	c.written += int64(len(p))
+	w.buf = append(w.buf, p...)
+	w.flush(c.MaxChunk)
+	if c.truncated {
+		w.flush(0)
+		c.sink(w.name, fmt.Sprintf("[output truncated: job exceeded %d bytes]\n", c.limit))
+	}
+	return n, nil
+}
+
+// flush emits chunks while at least min bytes are pending (everything when
+// min is 0), each cut after the last newline that fits in MaxChunk.
+// Callers hold c.mu.
+func (w *streamWriter) flush(min int) {
+	for len(w.buf) > 0 && len(w.buf) >= min {
+		cut := len(w.buf)
+		if cut > w.c.MaxChunk {
+			cut = w.c.MaxChunk
+			if i := bytes.LastIndexByte(w.buf[:cut], '\n'); i >= 0 {
+				cut = i + 1
+			}
+		}
+		w.c.sink(w.name, string(w.buf[:cut]))
+		w.buf = w.buf[cut:]
+	}
+	if len(w.buf) == 0 {
+		w.buf = nil
+	}
+}
+
+func (w *streamWriter) keepTail(p []byte) {
+	if len(p) >= TailBytes {
+		w.tail = append(w.tail[:0], p[len(p)-TailBytes:]...)
+		return
+	}
+	if drop := len(w.tail) + len(p) - TailBytes; drop > 0 {
+		w.tail = w.tail[drop:]
+	}
+	w.tail = append(w.tail, p...)
+}
+
+// scan splits p into lines and records directives. Callers hold c.mu.
+func (w *streamWriter) scan(p []byte) {
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.appendLine(p)
+			return
+		}
+		w.appendLine(p[:i])
+		w.scanLine()
+		p = p[i+1:]
+	}
+}
+
+func (w *streamWriter) appendLine(p []byte) {
+	if max := w.c.MaxLine; max > 0 && len(w.line)+len(p) > max {
+		p = p[:max-len(w.line)]
+		w.lineCut = true
+	}
+	w.line = append(w.line, p...)
+}
+
+func (w *streamWriter) scanLine() {
+	line := strings.TrimSpace(string(w.line))
+	cut := w.lineCut
+	w.line, w.lineCut = w.line[:0], false
+	if rest, ok := strings.CutPrefix(line, directivePrefix); ok {
+		name, value, _ := strings.Cut(strings.TrimSpace(rest), " ")
+		w.c.directives[name] = strings.TrimSpace(value)
+		w.c.cut[name] = cut
+	}
+}
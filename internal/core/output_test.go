@@ -0,0 +1,42 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDirectiveLongerThanMaxLine(t *testing.T) {
+	value := strings.Repeat("x", 6000)
+	tests := []struct {
+		maxLine int
+		wantCut bool
+	}{
+		{4096, true},
+		{8192, false},
+		{0, false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.maxLine), func(t *testing.T) {
+			c := NewOutputCapture(0, time.Hour, func(string, string) {})
+			c.MaxLine = tt.maxLine
+			w := c.Writer("stdout")
+			// written in pieces, as a pipe would deliver it
+			line := "##queuectl result " + value + "\nbye\n"
+			for len(line) > 0 {
+				n := min(len(line), 1000)
+				w.Write([]byte(line[:n]))
+				line = line[n:]
+			}
+			c.Close()
+
+			if got := c.DirectiveCut("result"); got != tt.wantCut {
+				t.Errorf("DirectiveCut() = %v, want %v", got, tt.wantCut)
+			}
+			if got := c.Directive("result"); !tt.wantCut && got != value {
+				t.Errorf("Directive() = %d bytes, want all %d", len(got), len(value))
+			}
+		})
+	}
+}
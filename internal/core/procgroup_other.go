@@ -0,0 +1,18 @@
+//go:build !unix
+
+package core
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// signalGroup falls back to the shell process itself where process groups
+// are unavailable; anything that can't be signalled is killed.
+func signalGroup(cmd *exec.Cmd, sig syscall.Signal) {
+	if err := cmd.Process.Signal(sig); err != nil {
+		_ = cmd.Process.Kill()
+	}
+}
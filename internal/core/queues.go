@@ -0,0 +1,72 @@
+package core
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// QueueSelector decides which queue a worker polls first. Queues are tried in
+// a weighted-random order on every poll, so a queue with weight 3 is tried
+// first three times as often as one with weight 1, while an idle queue never
+// blocks work waiting in the others.
+type QueueSelector struct {
+	names   []string
+	weights []int
+	total   int
+}
+
+// ParseQueueWeights parses "emails:3,reports:1" (weight defaults to 1).
+// An empty spec yields a selector that matches every queue.
+func ParseQueueWeights(spec string) (*QueueSelector, error) {
+	qs := &QueueSelector{}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" { continue }
+		name, w := part, 1
+		if i := strings.LastIndexByte(part, ':'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid weight in %q: must be a positive integer", part)
+			}
+			name, w = part[:i], n
+		}
+		if name == "" { return nil, fmt.Errorf("empty queue name in %q", part) }
+		if seen[name] { return nil, fmt.Errorf("queue %q listed twice", name) }
+		seen[name] = true
+		qs.names = append(qs.names, name)
+		qs.weights = append(qs.weights, w)
+		qs.total += w
+	}
+	return qs, nil
+}
+
+// Queues returns the subscribed queue names, or nil when subscribed to all.
+func (qs *QueueSelector) Queues() []string { return qs.names }
+
+// Order returns the queues to try for one poll. A nil selector or one with no
+// queues returns [""], which the store treats as "any queue".
+func (qs *QueueSelector) Order() []string {
+	if qs == nil || len(qs.names) == 0 {
+		return []string{""}
+	}
+	names := append([]string(nil), qs.names...)
+	weights := append([]int(nil), qs.weights...)
+	total := qs.total
+	out := make([]string, 0, len(names))
+	for len(names) > 0 {
+		r := rand.Intn(total)
+		i := 0
+		for r >= weights[i] {
+			r -= weights[i]
+			i++
+		}
+		out = append(out, names[i])
+		total -= weights[i]
+		names = append(names[:i], names[i+1:]...)
+		weights = append(weights[:i], weights[i+1:]...)
+	}
+	return out
+}
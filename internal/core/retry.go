@@ -1,29 +1,177 @@
 package core
 
 import (
-	"math"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
 	"time"
 
+	"github.com/shashidhxr/queueCTL/internal/config"
 	"github.com/shashidhxr/queueCTL/internal/store"
+	"github.com/shashidhxr/queueCTL/pkg/models"
 )
 
 type RetryManager struct {
-	storage     store.SQLiteStorage
-	backoffBase float64
+	storage store.Store
+	cfg     *config.Resolver
 }
 
-func NewRetryManager(store store.SQLiteStorage) *RetryManager {
+func NewRetryManager(store store.Store, cfg *config.Resolver) *RetryManager {
 	return &RetryManager{
-		storage:     store,
-		backoffBase: 2.0, // default
+		storage: store,
+		cfg:     cfg,
 	}
 }
 
-func (rm *RetryManager) CalculateBackoff(attempts int) time.Duration {
-	seconds := math.Pow(rm.backoffBase, float64(attempts))
-	maxDelay := 300.0
-	if seconds > maxDelay { // cap at 5 minutes
-		seconds = maxDelay
+// Strategy resolves j's backoff strategy. Each non-zero field of the job's
+// policy overrides its queue's, which overrides config.
+func (rm *RetryManager) Strategy(ctx context.Context, j *models.Job) BackoffStrategy {
+	p := rm.configBackoff()
+	if q, err := rm.storage.GetQueueSettings(ctx, j.Queue); err == nil {
+		p = mergeBackoff(p, q.Backoff)
 	}
-	return time.Duration(seconds) * time.Second
+	if j.BackoffBase > 0 { // jobs enqueued before --backoff set the policy's base
+		p.Base = j.BackoffBase
+	}
+	return rm.strategy(mergeBackoff(p, j.Backoff))
+}
+
+func (rm *RetryManager) configBackoff() models.BackoffPolicy {
+	c := config.Defaults()
+	if cfg, err := rm.cfg.Resolve(context.Background()); err == nil {
+		c = cfg
+	}
+	return models.BackoffPolicy{
+		Strategy: c.BackoffStrategy,
+		Base:     c.BackoffBase,
+		MinMS:    c.BackoffMin.Milliseconds(),
+		MaxMS:    c.BackoffMax.Milliseconds(),
+	}
+}
+
+// strategy builds p, falling back to the built-in default if p is invalid
+// (e.g. a queue's min was set above config's max).
+func (rm *RetryManager) strategy(p models.BackoffPolicy) BackoffStrategy {
+	s, err := NewBackoffStrategy(p)
+	if err != nil {
+		d := config.Defaults()
+		return BoundedBackoff{ExponentialBackoff{Base: d.BackoffBase, Unit: d.BackoffMin}, d.BackoffMin, d.BackoffMax}
+	}
+	return s
+}
+
+func mergeBackoff(p models.BackoffPolicy, over *models.BackoffPolicy) models.BackoffPolicy {
+	if over == nil {
+		return p
+	}
+	if over.Strategy != "" {
+		p.Strategy = over.Strategy
+	}
+	if over.Base > 0 {
+		p.Base = over.Base
+	}
+	if over.MinMS > 0 {
+		p.MinMS = over.MinMS
+	}
+	if over.MaxMS > 0 {
+		p.MaxMS = over.MaxMS
+	}
+	return p
+}
+
+// Failure is what a failed attempt leaves behind for the retry policy.
+type Failure struct {
+	ExitCode   *int   // nil or -1 when the command never exited on its own
+	Stderr     string // the tail of the attempt's stderr
+	RetryAfter string // value of the job's "##queuectl retry-after" directive, if any
+}
+
+// Decision is the retry policy's verdict on a failure. When Retry is false the
+// job goes straight to the DLQ; Reason says why. Otherwise Backoff picks the
+// delay, through store.FailOrSchedule.
+type Decision struct {
+	Retry   bool
+	Backoff BackoffStrategy
+	Reason  string
+}
+
+// Decide applies the job's retry policy, or its queue's when the job has none,
+// to a failed attempt. In order: a retry-after directive from the job always
+// retries after the requested delay; then fatal_match and retry_match are
+// tried against stderr; then fatal_on and retry_on against the exit code.
+// Anything left over is retried with the usual backoff. Retries are still
+// bounded by the job's max_retries.
+func (rm *RetryManager) Decide(ctx context.Context, j *models.Job, f Failure) Decision {
+	retry := Decision{Retry: true, Backoff: rm.Strategy(ctx, j)}
+	if f.RetryAfter != "" {
+		d, err := ParseRetryAfter(f.RetryAfter)
+		if err == nil {
+			return Decision{Retry: true, Backoff: FixedBackoff{d}, Reason: "retry-after requested by job"}
+		}
+	}
+
+	p := j.RetryPolicy
+	if p == nil {
+		q, err := rm.storage.GetQueueSettings(ctx, j.Queue)
+		if err != nil || q.RetryPolicy == nil {
+			return retry
+		}
+		p = q.RetryPolicy
+	}
+	if p.FatalMatch != "" {
+		if re, err := regexp.Compile(p.FatalMatch); err == nil && re.MatchString(f.Stderr) {
+			return Decision{Reason: "stderr matches fatal_match"}
+		}
+	}
+	if p.RetryMatch != "" {
+		if re, err := regexp.Compile(p.RetryMatch); err == nil && re.MatchString(f.Stderr) {
+			return retry
+		}
+	}
+	if f.ExitCode == nil || *f.ExitCode < 0 {
+		return retry // timed out or killed; exit code rules don't apply
+	}
+	code := *f.ExitCode
+	if slices.Contains(p.FatalOn, code) {
+		return Decision{Reason: fmt.Sprintf("exit code %d is fatal", code)}
+	}
+	if len(p.RetryOn) > 0 && !slices.Contains(p.RetryOn, code) {
+		return Decision{Reason: fmt.Sprintf("exit code %d is not retryable", code)}
+	}
+	return retry
+}
+
+// ValidateRetryPolicy checks that a policy's patterns compile.
+func ValidateRetryPolicy(p *models.RetryPolicy) error {
+	for _, pat := range []string{p.FatalMatch, p.RetryMatch} {
+		if _, err := regexp.Compile(pat); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pat, err)
+		}
+	}
+	return nil
+}
+
+// ParseRetryAfter accepts a Go duration ("90s", "5m") or whole seconds ("90").
+func ParseRetryAfter(s string) (time.Duration, error) {
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+		return time.Duration(n) * time.Second, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid retry-after %q", s)
+	}
+	return d, nil
+}
+
+// CheckBackoff reports whether the given policies, layered over config in
+// order as Strategy does, describe a valid strategy.
+func (rm *RetryManager) CheckBackoff(layers ...*models.BackoffPolicy) error {
+	p := rm.configBackoff()
+	for _, l := range layers {
+		p = mergeBackoff(p, l)
+	}
+	_, err := NewBackoffStrategy(p)
+	return err
 }
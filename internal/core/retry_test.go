@@ -0,0 +1,162 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shashidhxr/queueCTL/internal/config"
+	"github.com/shashidhxr/queueCTL/internal/store"
+	"github.com/shashidhxr/queueCTL/pkg/models"
+)
+
+func exitCode(n int) *int { return &n }
+
+func TestDecide(t *testing.T) {
+	st := store.NewMemoryStorage()
+	ctx := context.Background()
+	// Jobs on "strict" without a policy of their own fall back to this one.
+	if err := st.SaveQueueSettings(ctx, &models.QueueSettings{
+		Name:        "strict",
+		RetryPolicy: &models.RetryPolicy{RetryOn: []int{75}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	rm := NewRetryManager(st, config.NewResolver(st))
+	fixed := &models.BackoffPolicy{Strategy: models.BackoffFixed, MinMS: 1000}
+
+	tests := []struct {
+		name      string
+		job       models.Job
+		failure   Failure
+		wantRetry bool
+		wantDelay time.Duration // checked when retrying
+	}{
+		{
+			name:      "no policy retries with backoff",
+			job:       models.Job{Queue: "default", Backoff: fixed},
+			failure:   Failure{ExitCode: exitCode(1)},
+			wantRetry: true, wantDelay: time.Second,
+		},
+		{
+			name:    "fatal exit code",
+			job:     models.Job{Queue: "default", RetryPolicy: &models.RetryPolicy{FatalOn: []int{2}}},
+			failure: Failure{ExitCode: exitCode(2)},
+		},
+		{
+			name:    "exit code not in retry_on",
+			job:     models.Job{Queue: "default", RetryPolicy: &models.RetryPolicy{RetryOn: []int{75}}},
+			failure: Failure{ExitCode: exitCode(1)},
+		},
+		{
+			name:      "exit code in retry_on",
+			job:       models.Job{Queue: "default", Backoff: fixed, RetryPolicy: &models.RetryPolicy{RetryOn: []int{75}}},
+			failure:   Failure{ExitCode: exitCode(75)},
+			wantRetry: true, wantDelay: time.Second,
+		},
+		{
+			name:    "stderr matches fatal_match",
+			job:     models.Job{Queue: "default", RetryPolicy: &models.RetryPolicy{FatalMatch: `permission denied`}},
+			failure: Failure{ExitCode: exitCode(1), Stderr: "open /etc/x: permission denied"},
+		},
+		{
+			name:      "retry_match beats fatal_on",
+			job:       models.Job{Queue: "default", Backoff: fixed, RetryPolicy: &models.RetryPolicy{FatalOn: []int{1}, RetryMatch: `connection reset`}},
+			failure:   Failure{ExitCode: exitCode(1), Stderr: "read: connection reset by peer"},
+			wantRetry: true, wantDelay: time.Second,
+		},
+		{
+			name:      "killed ignores exit code rules",
+			job:       models.Job{Queue: "default", Backoff: fixed, RetryPolicy: &models.RetryPolicy{RetryOn: []int{75}}},
+			failure:   Failure{ExitCode: exitCode(-1)},
+			wantRetry: true, wantDelay: time.Second,
+		},
+		{
+			name:      "retry-after directive wins",
+			job:       models.Job{Queue: "default", RetryPolicy: &models.RetryPolicy{FatalOn: []int{1}}},
+			failure:   Failure{ExitCode: exitCode(1), RetryAfter: "90"},
+			wantRetry: true, wantDelay: 90 * time.Second,
+		},
+		{
+			name:    "falls back to the queue's policy",
+			job:     models.Job{Queue: "strict"},
+			failure: Failure{ExitCode: exitCode(1)},
+		},
+		{
+			name:      "job policy overrides the queue's",
+			job:       models.Job{Queue: "strict", Backoff: fixed, RetryPolicy: &models.RetryPolicy{}},
+			failure:   Failure{ExitCode: exitCode(1)},
+			wantRetry: true, wantDelay: time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := rm.Decide(ctx, &tt.job, tt.failure)
+			if d.Retry != tt.wantRetry {
+				t.Fatalf("Decide() = %+v, want Retry=%v", d, tt.wantRetry)
+			}
+			if !d.Retry && d.Reason == "" {
+				t.Errorf("Decide() = %+v, want a reason for not retrying", d)
+			}
+			if d.Retry {
+				if got := d.Backoff.Next(tt.job.Attempts+1, 0); got != tt.wantDelay {
+					t.Errorf("Decide() backoff delay = %s, want %s", got, tt.wantDelay)
+				}
+			}
+		})
+	}
+}
+
+func TestStrategyLayersJobOverQueueOverConfig(t *testing.T) {
+	st := store.NewMemoryStorage()
+	ctx := context.Background()
+	if err := st.SaveQueueSettings(ctx, &models.QueueSettings{
+		Name:    "slow",
+		Backoff: &models.BackoffPolicy{Strategy: models.BackoffLinear, MinMS: 2000},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	rm := NewRetryManager(st, config.NewResolver(st))
+
+	tests := []struct {
+		name string
+		job  models.Job
+		want time.Duration // delay after the third failure
+	}{
+		{"config default exponential", models.Job{Queue: "default"}, 8 * time.Second},
+		{"job base overrides config", models.Job{Queue: "default", BackoffBase: 3}, 27 * time.Second},
+		{"policy base overrides legacy job base", models.Job{Queue: "default", BackoffBase: 3, Backoff: &models.BackoffPolicy{Base: 1.5}}, 3375 * time.Millisecond},
+		{"queue policy", models.Job{Queue: "slow"}, 6 * time.Second},
+		{"job min overrides the queue's", models.Job{Queue: "slow", Backoff: &models.BackoffPolicy{MinMS: 1000}}, 3 * time.Second},
+		{"job strategy overrides the queue's", models.Job{Queue: "slow", Backoff: &models.BackoffPolicy{Strategy: models.BackoffFixed}}, 2 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rm.Strategy(ctx, &tt.job).Next(3, 0); got != tt.want {
+				t.Errorf("Strategy().Next(attempt 3) = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "90", want: 90 * time.Second},
+		{in: "0", want: 0},
+		{in: "1m30s", want: 90 * time.Second},
+		{in: "250ms", want: 250 * time.Millisecond},
+		{in: "-5", wantErr: true},
+		{in: "-1s", wantErr: true},
+		{in: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRetryAfter(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseRetryAfter(%q) = %s, %v; want %s, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package core
+
+import "os"
+
+// processStats has no portable source for signals or peak memory.
+func processStats(ps *os.ProcessState) (signal string, maxRSSKB int64) {
+	return "", 0
+}
@@ -0,0 +1,36 @@
+//go:build unix
+
+package core
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"syscall"
+)
+
+var signalNames = map[syscall.Signal]string{
+	syscall.SIGHUP: "SIGHUP", syscall.SIGINT: "SIGINT", syscall.SIGQUIT: "SIGQUIT",
+	syscall.SIGILL: "SIGILL", syscall.SIGABRT: "SIGABRT", syscall.SIGFPE: "SIGFPE",
+	syscall.SIGKILL: "SIGKILL", syscall.SIGSEGV: "SIGSEGV", syscall.SIGPIPE: "SIGPIPE",
+	syscall.SIGALRM: "SIGALRM", syscall.SIGTERM: "SIGTERM", syscall.SIGBUS: "SIGBUS",
+	syscall.SIGUSR1: "SIGUSR1", syscall.SIGUSR2: "SIGUSR2", syscall.SIGXCPU: "SIGXCPU",
+}
+
+// processStats returns the signal that terminated the process, if any, and
+// its peak resident set size in KB.
+func processStats(ps *os.ProcessState) (signal string, maxRSSKB int64) {
+	if ws, ok := ps.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		signal = signalNames[ws.Signal()]
+		if signal == "" {
+			signal = fmt.Sprintf("signal %d", int(ws.Signal()))
+		}
+	}
+	if ru, ok := ps.SysUsage().(*syscall.Rusage); ok {
+		maxRSSKB = int64(ru.Maxrss)
+		if runtime.GOOS == "darwin" {
+			maxRSSKB /= 1024 // reported in bytes there
+		}
+	}
+	return signal, maxRSSKB
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shashidhxr/queueCTL/internal/config"
+	"github.com/shashidhxr/queueCTL/internal/store"
+	"github.com/shashidhxr/queueCTL/pkg/models"
+)
+
+// Scheduler turns due schedules into jobs. Any number of schedulers may run
+// against the same database; store.FireSchedule guarantees each tick is
+// materialized once.
+type Scheduler struct {
+	storage store.Store
+	cfg     *config.Resolver
+
+	// Tolerance is how late a tick may be picked up and still count as on
+	// time under the "skip" catch-up policy.
+	Tolerance time.Duration
+	// MaxCatchUp bounds how many jobs "run-all" creates for one schedule
+	// in a single pass; older ticks beyond that are dropped.
+	MaxCatchUp int
+}
+
+func NewScheduler(storage store.Store, cfg *config.Resolver) *Scheduler {
+	return &Scheduler{
+		storage:    storage,
+		cfg:        cfg,
+		Tolerance:  30 * time.Second,
+		MaxCatchUp: 100,
+	}
+}
+
+// Tick fires every schedule due at now and returns the number of jobs created.
+func (s *Scheduler) Tick(ctx context.Context, now time.Time) (int, error) {
+	due, err := s.storage.DueSchedules(ctx, now)
+	if err != nil { return 0, err }
+	if len(due) == 0 { return 0, nil }
+	// Jobs get the same default timeout as `enqueue` would give them.
+	c, err := s.cfg.Resolve(ctx)
+	if err != nil { return 0, err }
+	timeout := int(c.JobTimeout.Round(time.Second) / time.Second)
+
+	created := 0
+	for i := range due {
+		sc := &due[i]
+		spec, err := ParseSchedule(sc.Spec)
+		if err != nil {
+			return created, fmt.Errorf("schedule %s: %w", sc.ID, err)
+		}
+		ticks, next := s.plan(spec, sc, now)
+		jobs := make([]*models.Job, 0, len(ticks))
+		for _, t := range ticks {
+			jobs = append(jobs, &models.Job{
+				ID:         tickJobID(sc.ID, t),
+				Command:    sc.Command,
+				Queue:      sc.Queue,
+				State:      models.StatePending,
+				MaxRetries: sc.MaxRetries,
+
+				TimeoutSeconds: timeout,
+			})
+		}
+		won, err := s.storage.FireSchedule(ctx, sc, next, jobs)
+		if err != nil {
+			return created, fmt.Errorf("schedule %s: %w", sc.ID, err)
+		}
+		if won { created += len(jobs) }
+	}
+	return created, nil
+}
+
+// plan returns the ticks of sc to materialize according to its catch-up
+// policy, and the first tick after now.
+func (s *Scheduler) plan(spec Schedule, sc *models.Schedule, now time.Time) ([]time.Time, time.Time) {
+	var missed []time.Time
+	t := sc.NextRunAt
+	for !t.IsZero() && !t.After(now) {
+		missed = append(missed, t)
+		if len(missed) > s.MaxCatchUp {
+			missed = missed[1:]
+		}
+		t = spec.Next(t)
+	}
+	if len(missed) == 0 {
+		return nil, t
+	}
+
+	last := missed[len(missed)-1]
+	switch sc.CatchUp {
+	case models.CatchUpRunAll:
+		return missed, t
+	case models.CatchUpRunOnce:
+		return []time.Time{last}, t
+	default: // skip
+		if now.Sub(last) <= s.Tolerance {
+			return []time.Time{last}, t
+		}
+		return nil, t
+	}
+}
+
+var scheduleNS = uuid.NewSHA1(uuid.NameSpaceURL, []byte("queuectl:schedule"))
+
+func tickJobID(scheduleID string, tick time.Time) string {
+	return uuid.NewSHA1(scheduleNS, []byte(scheduleID+"@"+strconv.FormatInt(tick.Unix(), 10))).String()
+}
@@ -0,0 +1,36 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shashidhxr/queueCTL/internal/config"
+	"github.com/shashidhxr/queueCTL/internal/store"
+	"github.com/shashidhxr/queueCTL/pkg/models"
+)
+
+func TestTickCreatesJobsWithConfiguredTimeout(t *testing.T) {
+	st := store.NewMemoryStorage()
+	ctx := context.Background()
+	if err := st.SetConfig(ctx, "job_timeout", "2m"); err != nil {
+		t.Fatal(err)
+	}
+	tick := time.Date(2025, time.January, 15, 10, 0, 0, 0, time.UTC)
+	sc := &models.Schedule{ID: "nightly", Spec: "@every 1h", Command: "true", MaxRetries: 2, NextRunAt: tick}
+	if err := st.SaveSchedule(ctx, sc); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := NewScheduler(st, config.NewResolver(st)).Tick(ctx, tick.Add(time.Second))
+	if err != nil || n != 1 {
+		t.Fatalf("Tick() = %d, %v; want 1 job", n, err)
+	}
+	j, err := st.GetJob(ctx, tickJobID(sc.ID, tick))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.TimeoutSeconds != 120 || j.MaxRetries != 2 || j.Queue != models.DefaultQueue {
+		t.Errorf("job = timeout %ds, max_retries %d, queue %q; want 120s, 2, %q", j.TimeoutSeconds, j.MaxRetries, j.Queue, models.DefaultQueue)
+	}
+}
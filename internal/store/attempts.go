@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/shashidhxr/queueCTL/pkg/models"
+)
+
+// StartAttempt records that worker began running a job under lease leaseID and
+// returns the attempt's id for FinishAttempt and its 1-based number.
+func (s *SQLiteStorage) StartAttempt(ctx context.Context, jobID, leaseID, worker string) (id int64, number int, err error) {
+	res, err := s.db.ExecContext(ctx, `
+INSERT INTO job_attempts(job_id, number, worker, lease_id, started_at)
+VALUES(?, (SELECT COUNT(*) + 1 FROM job_attempts WHERE job_id = ?), ?, ?, ?)`,
+		jobID, jobID, worker, leaseID, time.Now().UTC())
+	if err != nil { return 0, 0, err }
+	if id, err = res.LastInsertId(); err != nil { return 0, 0, err }
+	err = s.db.QueryRowContext(ctx, `SELECT number FROM job_attempts WHERE id=?`, id).Scan(&number)
+	return id, number, err
+}
+
+// FinishAttempt closes an attempt with how its process ended. u.ExitCode is
+// nil when the command never produced one (e.g. it could not be started).
+func (s *SQLiteStorage) FinishAttempt(ctx context.Context, id int64, u models.Usage, errStr string) error {
+	var code any
+	if u.ExitCode != nil { code = *u.ExitCode }
+	_, err := s.db.ExecContext(ctx, `
+UPDATE job_attempts SET ended_at=?, exit_code=?, error=?, signal=?, wall_ms=?, user_ms=?, sys_ms=?, max_rss_kb=?
+WHERE id=?`, time.Now().UTC(), code, errStr, u.Signal, u.WallMS, u.UserMS, u.SysMS, u.MaxRSSKB, id)
+	return err
+}
+
+const attemptCols = `id, job_id, number, worker, lease_id, started_at, ended_at, exit_code, error, signal, wall_ms, user_ms, sys_ms, max_rss_kb`
+
+func (s *SQLiteStorage) GetAttempts(ctx context.Context, jobID string) ([]models.Attempt, error) {
+	return queryAttempts(ctx, s.db, `
+SELECT `+attemptCols+` FROM job_attempts WHERE job_id = ? ORDER BY number ASC`, jobID)
+}
+
+// LastAttempts returns the latest attempt of each of the given jobs that has
+// run at least once, keyed by job id.
+func (s *SQLiteStorage) LastAttempts(ctx context.Context, jobIDs []string) (map[string]models.Attempt, error) {
+	out := map[string]models.Attempt{}
+	if len(jobIDs) == 0 { return out, nil }
+	args := make([]any, len(jobIDs))
+	for i, id := range jobIDs { args[i] = id }
+	attempts, err := queryAttempts(ctx, s.db, `
+SELECT `+attemptCols+` FROM job_attempts a
+WHERE job_id IN (?`+strings.Repeat(",?", len(jobIDs)-1)+`)
+  AND number = (SELECT MAX(number) FROM job_attempts b WHERE b.job_id = a.job_id)`, args...)
+	if err != nil { return nil, err }
+	for _, a := range attempts { out[a.JobID] = a }
+	return out, nil
+}
+
+func queryAttempts(ctx context.Context, db querier, q string, args ...any) ([]models.Attempt, error) {
+	rows, err := db.QueryContext(ctx, q, args...)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	var out []models.Attempt
+	for rows.Next() {
+		var a models.Attempt
+		var ended sql.NullTime
+		var code sql.NullInt64
+		if err := rows.Scan(&a.ID, &a.JobID, &a.Number, &a.Worker, &a.LeaseID, &a.StartedAt, &ended, &code, &a.Error,
+			&a.Signal, &a.WallMS, &a.UserMS, &a.SysMS, &a.MaxRSSKB); err != nil {
+			return nil, err
+		}
+		if ended.Valid { t := ended.Time; a.EndedAt = &t }
+		if code.Valid { c := int(code.Int64); a.ExitCode = &c }
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/shashidhxr/queueCTL/pkg/models"
+)
+
+// benchQueueDepth is how many jobs are pending when leasing starts; the queue
+// is refilled to this depth whenever it runs dry.
+const benchQueueDepth = 10000
+
+// BenchmarkAcquireJobs leases jobs one per call and in batches of 32, as
+// `worker start --batch 1` and the default do. ns/op is per leased job.
+//
+//	go test -run '^$' -bench AcquireJobs ./internal/store/
+func BenchmarkAcquireJobs(b *testing.B) {
+	for _, backend := range []string{"memory", "sqlite"} {
+		for _, n := range []int{1, 32} {
+			b.Run(fmt.Sprintf("%s/n=%d", backend, n), func(b *testing.B) {
+				s := openTestStore(b, backend)
+				ctx := context.Background()
+				seeded, pending := 0, 0
+				b.ResetTimer()
+				for leased := 0; leased < b.N; {
+					if pending == 0 {
+						b.StopTimer()
+						for i := 0; i < benchQueueDepth; i++ {
+							j := &models.Job{ID: fmt.Sprintf("bench-%d", seeded), Command: "true", MaxRetries: 3, TimeoutSeconds: 30}
+							if err := s.SaveJob(ctx, j); err != nil { b.Fatal(err) }
+							seeded++
+						}
+						pending = benchQueueDepth
+						b.StartTimer()
+					}
+					jobs, err := s.AcquireJobs(ctx, "", n)
+					if err != nil { b.Fatal(err) }
+					if len(jobs) == 0 { b.Fatalf("no job leased with %d pending", pending) }
+					leased += len(jobs)
+					pending -= len(jobs)
+				}
+			})
+		}
+	}
+}
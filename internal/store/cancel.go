@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/shashidhxr/queueCTL/pkg/models"
+)
+
+// CancelJob cancels a pending job immediately, or flags a running one so the
+// worker holding its lease stops it. It returns the state the job was in.
+func (s *SQLiteStorage) CancelJob(ctx context.Context, id string) (models.JobState, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil { return "", err }
+	defer func() { _ = tx.Rollback() }()
+
+	var state models.JobState
+	if err := tx.QueryRowContext(ctx, `SELECT state FROM jobs WHERE id=?`, id).Scan(&state); err != nil {
+		if errors.Is(err, sql.ErrNoRows) { return "", fmt.Errorf("job %s not found", id) }
+		return "", err
+	}
+	switch state {
+	case models.StatePending:
+		_, err = tx.ExecContext(ctx, `
+UPDATE jobs SET state='cancelled', error='cancelled', updated_at=CURRENT_TIMESTAMP WHERE id=?`, id)
+	case models.StateProcessing:
+		_, err = tx.ExecContext(ctx, `UPDATE jobs SET cancel_requested=1 WHERE id=?`, id)
+	default:
+		return state, fmt.Errorf("job %s is already %s", id, state)
+	}
+	if err != nil { return "", err }
+	return state, tx.Commit()
+}
+
+// CancelJobs applies CancelJob to every pending and/or processing job matching
+// f (f.State may be pending, processing or empty for both). It returns how many
+// pending jobs were cancelled and how many running jobs were asked to stop.
+func (s *SQLiteStorage) CancelJobs(ctx context.Context, f JobFilter) (cancelled, requested int, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil { return 0, 0, err }
+	defer func() { _ = tx.Rollback() }()
+
+	if f.State == "" || f.State == models.StatePending {
+		res, err := tx.ExecContext(ctx, `
+UPDATE jobs SET state='cancelled', error='cancelled', updated_at=CURRENT_TIMESTAMP
+WHERE state='pending' AND (? = '' OR queue = ?)`, f.Queue, f.Queue)
+		if err != nil { return 0, 0, err }
+		n, _ := res.RowsAffected()
+		cancelled = int(n)
+	}
+	if f.State == "" || f.State == models.StateProcessing {
+		res, err := tx.ExecContext(ctx, `
+UPDATE jobs SET cancel_requested=1
+WHERE state='processing' AND cancel_requested=0 AND (? = '' OR queue = ?)`, f.Queue, f.Queue)
+		if err != nil { return 0, 0, err }
+		n, _ := res.RowsAffected()
+		requested = int(n)
+	}
+	return cancelled, requested, tx.Commit()
+}
@@ -2,27 +2,21 @@ package store
 
 import (
 	"context"
-	"strconv"
-
-	"github.com/shashidhxr/queueCTL/pkg/models"
 )
 
-func (s *SQLiteStorage) GetConfig(ctx context.Context) (*models.Config, error) {
-	cfg := &models.Config{ MaxRetries: 3, BackoffBase: 2 }
+// GetConfigValues returns the raw key/value pairs stored by SetConfig.
+// Parsing and defaults live in internal/config.
+func (s *SQLiteStorage) GetConfigValues(ctx context.Context) (map[string]string, error) {
 	rows, err := s.db.QueryContext(ctx, `SELECT key, value FROM config`)
 	if err != nil { return nil, err }
 	defer rows.Close()
+	out := map[string]string{}
 	for rows.Next() {
 		var k, v string
 		if err := rows.Scan(&k, &v); err != nil { return nil, err }
-		switch k {
-		case "max_retries":
-			if n, err := strconv.Atoi(v); err == nil { cfg.MaxRetries = n }
-		case "backoff_base":
-			if n, err := strconv.Atoi(v); err == nil { cfg.BackoffBase = n }
-		}
+		out[k] = v
 	}
-	return cfg, rows.Err()
+	return out, rows.Err()
 }
 
 func (s *SQLiteStorage) SetConfig(ctx context.Context, key, value string) error {
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shashidhxr/queueCTL/pkg/models"
+)
+
+var ErrNotInDLQ = errors.New("job not found in DLQ")
+
+// DLQFilter narrows which dead jobs a DLQ operation applies to.
+// Zero values mean "no restriction".
+type DLQFilter struct {
+	Queue         string
+	ErrorContains string    // substring match on the last error
+	OlderThan     time.Time // only jobs that died at or before this time
+	Limit         int       // max rows for listing; ignored by bulk operations
+}
+
+const dlqWhere = `
+WHERE state='dead'
+  AND (? = '' OR queue = ?)
+  AND (? = '' OR error LIKE '%' || ? || '%' ESCAPE '\')
+  AND (? IS NULL OR updated_at <= ?)`
+
+func (f DLQFilter) args() []any {
+	var older any
+	if !f.OlderThan.IsZero() { older = f.OlderThan.UTC() }
+	contains := likeEscape(f.ErrorContains)
+	return []any{f.Queue, f.Queue, contains, contains, older, older}
+}
+
+// likeEscaper makes %, _ and the escape character itself match literally in
+// a LIKE pattern with ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func likeEscape(s string) string { return likeEscaper.Replace(s) }
+
+func (s *SQLiteStorage) GetDLQJobs(ctx context.Context, f DLQFilter) ([]models.Job, error) {
+	limit := f.Limit
+	if limit <= 0 { limit = 50 }
+	return queryJobs(ctx, s.db, `
+SELECT `+jobCols+`
+FROM jobs`+dlqWhere+`
+ORDER BY updated_at DESC
+LIMIT ?`, append(f.args(), limit)...)
+}
+
+// resetDead is the single statement used to resurrect dead jobs, so attempts,
+// error and scheduling state are always cleared together.
+const resetDead = `
+UPDATE jobs
+SET state='pending', attempts=0, error='', next_retry=NULL, retry_delay_ms=0, worker_id=NULL, lease_until=NULL, updated_at=CURRENT_TIMESTAMP`
+
+func (s *SQLiteStorage) RetryDLQJob(ctx context.Context, jobID string) error {
+	res, err := s.db.ExecContext(ctx, resetDead+` WHERE id=? AND state='dead'`, jobID)
+	if err != nil { return err }
+	if n, _ := res.RowsAffected(); n != 1 {
+		return fmt.Errorf("%w: %s", ErrNotInDLQ, jobID)
+	}
+	s.jobsChanged()
+	return nil
+}
+
+func (s *SQLiteStorage) RetryDLQJobs(ctx context.Context, f DLQFilter) (int, error) {
+	res, err := s.db.ExecContext(ctx, resetDead+dlqWhere, f.args()...)
+	if err != nil { return 0, err }
+	n, err := res.RowsAffected()
+	if n > 0 { s.jobsChanged() }
+	return int(n), err
+}
+
+// PurgeDLQ deletes matching dead jobs together with their logs and attempts.
+func (s *SQLiteStorage) PurgeDLQ(ctx context.Context, f DLQFilter) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil { return 0, err }
+	defer func() { _ = tx.Rollback() }()
+
+	for _, table := range []string{"job_logs", "job_attempts"} {
+		if _, err := tx.ExecContext(ctx, `
+DELETE FROM `+table+` WHERE job_id IN (SELECT id FROM jobs`+dlqWhere+`)`, f.args()...); err != nil {
+			return 0, err
+		}
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM jobs`+dlqWhere, f.args()...)
+	if err != nil { return 0, err }
+	n, err := res.RowsAffected()
+	if err != nil { return 0, err }
+	return int(n), tx.Commit()
+}
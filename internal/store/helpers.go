@@ -1,8 +1,56 @@
 package store
 
-import "time"
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// rowQuerier is satisfied by both *sql.DB and *sql.Tx.
+type rowQuerier interface {
+	querier
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
 
 func nullableTime(t *time.Time) any {
 	if t == nil { return nil }
 	return *t
 }
+
+// nullableString stores "" as NULL.
+func nullableString(s string) any {
+	if s == "" { return nil }
+	return s
+}
+
+// nullableJSON stores v as a JSON document, or NULL when v is a nil pointer.
+func nullableJSON[T any](v *T) (any, error) {
+	if v == nil { return nil, nil }
+	b, err := json.Marshal(v)
+	if err != nil { return nil, err }
+	return string(b), nil
+}
+
+// scanJSON decodes a column written by nullableJSON.
+func scanJSON[T any](col sql.NullString) (*T, error) {
+	if !col.Valid || col.String == "" { return nil, nil }
+	v := new(T)
+	if err := json.Unmarshal([]byte(col.String), v); err != nil { return nil, err }
+	return v, nil
+}
@@ -2,32 +2,76 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/shashidhxr/queueCTL/pkg/models"
 )
 
-type store interface {
-	// Job operations
-    SaveJob(ctx context.Context, job *models.Job) error
-    GetJob(ctx context.Context, id string) (*models.Job, error)
-    UpdateJobState(ctx context.Context, id string, state models.JobState) error
-    ListJobs(ctx context.Context, state models.JobState) ([]*models.Job, error)
-    GetPendingJobs(ctx context.Context) ([]*models.Job, error)
-    
-    // Worker operations
-    AcquireJob(ctx context.Context) (*models.Job, error)
-    
-    // DLQ operations
-    GetDLQJobs(ctx context.Context) ([]*models.Job, error)
-    RetryDLQJob(ctx context.Context, jobID string) error
-    
-    // Config operations
-    GetConfig(ctx context.Context) (*models.Config, error)
-    SetConfig(ctx context.Context, config *models.Config) error
-    
-    // Status
-    GetJobStats(ctx context.Context) (map[models.JobState]int, error)
-    
-    Close() error
-	
-}
\ No newline at end of file
+// Store is everything the CLI, the worker and core need from a storage
+// backend. Methods that take a workerID (or a job leased by AcquireJob) are
+// fenced on that lease and return ErrLeaseLost once it is gone.
+type Store interface {
+	// Jobs
+	SaveJob(ctx context.Context, job *models.Job) error
+	GetJob(ctx context.Context, id string) (*models.Job, error)
+	UpdateJobState(ctx context.Context, id string, state models.JobState) error
+	ListJobs(ctx context.Context, f JobFilter) ([]models.Job, error)
+	SetPriority(ctx context.Context, id string, priority int) error
+	CancelJob(ctx context.Context, id string) (models.JobState, error)
+	CancelJobs(ctx context.Context, f JobFilter) (cancelled, requested int, err error)
+
+	// Worker: leasing and outcomes
+	AcquireJob(ctx context.Context, queue string) (*models.Job, error)
+	AcquireJobs(ctx context.Context, queue string, n int) ([]models.Job, error)
+	AcquireJobByID(ctx context.Context, id string) (*models.Job, error)
+	RenewLease(ctx context.Context, id, workerID string, until time.Time) (cancelRequested bool, err error)
+	RequeueExpiredLeases(ctx context.Context) error
+	SetCompleted(ctx context.Context, id, workerID, result string) error
+	SetCancelled(ctx context.Context, id, workerID string) error
+	SetDead(ctx context.Context, j *models.Job, errStr string) error
+	FailOrSchedule(ctx context.Context, j *models.Job, backoff Backoff, errStr string) error
+
+	// Attempts and logs
+	StartAttempt(ctx context.Context, jobID, leaseID, worker string) (id int64, number int, err error)
+	FinishAttempt(ctx context.Context, id int64, u models.Usage, errStr string) error
+	GetAttempts(ctx context.Context, jobID string) ([]models.Attempt, error)
+	LastAttempts(ctx context.Context, jobIDs []string) (map[string]models.Attempt, error)
+	AppendLog(ctx context.Context, jobID string, attempt int, stream, chunk string) error
+	GetLogs(ctx context.Context, jobID string, f LogFilter) ([]LogLine, error)
+	TailLogs(ctx context.Context, jobID string, n int) ([]LogLine, error)
+
+	// DLQ
+	GetDLQJobs(ctx context.Context, f DLQFilter) ([]models.Job, error)
+	RetryDLQJob(ctx context.Context, jobID string) error
+	RetryDLQJobs(ctx context.Context, f DLQFilter) (int, error)
+	PurgeDLQ(ctx context.Context, f DLQFilter) (int, error)
+
+	// Recurring schedules
+	SaveSchedule(ctx context.Context, sc *models.Schedule) error
+	GetSchedule(ctx context.Context, id string) (*models.Schedule, error)
+	ListSchedules(ctx context.Context) ([]models.Schedule, error)
+	DueSchedules(ctx context.Context, now time.Time) ([]models.Schedule, error)
+	PauseSchedule(ctx context.Context, id string) error
+	ResumeSchedule(ctx context.Context, id string, next time.Time) error
+	DeleteSchedule(ctx context.Context, id string) error
+	FireSchedule(ctx context.Context, sc *models.Schedule, next time.Time, jobs []*models.Job) (bool, error)
+
+	// Queue settings
+	GetQueueSettings(ctx context.Context, name string) (*models.QueueSettings, error)
+	ListQueueSettings(ctx context.Context) ([]models.QueueSettings, error)
+	SaveQueueSettings(ctx context.Context, q *models.QueueSettings) error
+	DeleteQueueSettings(ctx context.Context, name string) error
+
+	// Config
+	GetConfigValues(ctx context.Context) (map[string]string, error)
+	SetConfig(ctx context.Context, key, value string) error
+
+	// Status
+	GetJobStats(ctx context.Context) (map[models.JobState]int, error)
+	GetQueueStats(ctx context.Context) (map[string]map[models.JobState]int, error)
+	GetAttemptStats(ctx context.Context, since time.Time) (map[string]AttemptStats, error)
+
+	Close() error
+}
+
+var _ Store = (*SQLiteStorage)(nil)
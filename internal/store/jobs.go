@@ -3,7 +3,7 @@ package store
 import (
 	"context"
 	"database/sql"
-	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,75 +15,165 @@ func (s *SQLiteStorage) SaveJob(ctx context.Context, j *models.Job) error {
 	if j.State == "" {
 		j.State = models.StatePending
 	}
-	if j.MaxRetries == 0 {
-		j.MaxRetries = 3
+	if j.Queue == "" {
+		j.Queue = models.DefaultQueue
 	}
 	j.CreatedAt, j.UpdatedAt = now, now
-	_, err := s.db.ExecContext(ctx, `
-INSERT INTO jobs (id, command, state, attempts, max_retries, error, next_retry, created_at, updated_at, timeout_seconds)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		j.ID, j.Command, j.State, j.Attempts, j.MaxRetries, j.Error, nullableTime(j.NextRetry), j.CreatedAt, j.UpdatedAt, j.TimeoutSeconds)
-	return err
+	if _, err := insertJob(ctx, s.db, "INSERT", j); err != nil { return err }
+	s.jobsChanged()
+	return nil
 }
 
-func (s *SQLiteStorage) GetJob(ctx context.Context, id string) (*models.Job, error) {
-	row := s.db.QueryRowContext(ctx, `
-SELECT id, command, state, attempts, max_retries, error, next_retry, created_at, updated_at, timeout_seconds, worker_id, lease_until
-FROM jobs WHERE id = ?`, id)
+func insertJob(ctx context.Context, ex execer, verb string, j *models.Job) (sql.Result, error) {
+	policy, err := nullableJSON(j.RetryPolicy)
+	if err != nil { return nil, err }
+	backoff, err := nullableJSON(j.Backoff)
+	if err != nil { return nil, err }
+	return ex.ExecContext(ctx, verb+` INTO jobs (id, command, queue, state, attempts, max_retries, error, next_retry, run_at, priority, created_at, updated_at, timeout_seconds, backoff_base, retry_policy, backoff)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		j.ID, j.Command, j.Queue, j.State, j.Attempts, j.MaxRetries, j.Error, nullableTime(j.NextRetry), nullableTime(j.RunAt), j.Priority, j.CreatedAt, j.UpdatedAt, j.TimeoutSeconds, j.BackoffBase, policy, backoff)
+}
+
+// jobCols is the column list scanJob expects, in order.
+const jobCols = `id, command, queue, state, attempts, max_retries, error, next_retry, run_at, priority, created_at, updated_at, timeout_seconds, backoff_base, worker_id, lease_until, cancel_requested, retry_policy, backoff, retry_delay_ms, result`
+
+func scanJob(r rowScanner) (*models.Job, error) {
 	var j models.Job
-	var next, lease sql.NullTime
-	var workerID sql.NullString
-	if err := row.Scan(&j.ID, &j.Command, &j.State, &j.Attempts, &j.MaxRetries, &j.Error, &next, &j.CreatedAt, &j.UpdatedAt, &j.TimeoutSeconds, &workerID, &lease); err != nil {
+	var next, runAt, lease sql.NullTime
+	var workerID, policy, backoff, result sql.NullString
+	if err := r.Scan(&j.ID, &j.Command, &j.Queue, &j.State, &j.Attempts, &j.MaxRetries, &j.Error, &next, &runAt, &j.Priority, &j.CreatedAt, &j.UpdatedAt, &j.TimeoutSeconds, &j.BackoffBase, &workerID, &lease, &j.CancelRequested, &policy, &backoff, &j.RetryDelayMS, &result); err != nil {
 		return nil, err
 	}
+	var err error
+	if j.RetryPolicy, err = scanJSON[models.RetryPolicy](policy); err != nil { return nil, err }
+	if j.Backoff, err = scanJSON[models.BackoffPolicy](backoff); err != nil { return nil, err }
 	if next.Valid { t := next.Time; j.NextRetry = &t }
+	if runAt.Valid { t := runAt.Time; j.RunAt = &t }
 	if lease.Valid { t := lease.Time; j.LeaseUntil = &t }
 	if workerID.Valid { v := workerID.String; j.WorkerID = &v }
+	j.Result = result.String
 	return &j, nil
 }
 
+func queryJobs(ctx context.Context, db querier, q string, args ...any) ([]models.Job, error) {
+	rows, err := db.QueryContext(ctx, q, args...)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	var out []models.Job
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil { return nil, err }
+		out = append(out, *j)
+	}
+	return out, rows.Err()
+}
+
+func (s *SQLiteStorage) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	return scanJob(s.db.QueryRowContext(ctx, `SELECT `+jobCols+` FROM jobs WHERE id = ?`, id))
+}
+
 func (s *SQLiteStorage) UpdateJobState(ctx context.Context, id string, state models.JobState) error {
 	_, err := s.db.ExecContext(ctx, `UPDATE jobs SET state=?, updated_at=CURRENT_TIMESTAMP WHERE id=?`, state, id)
 	return err
 }
 
 
-func (s *SQLiteStorage) AcquireJob(ctx context.Context) (*models.Job, error) {
-	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+// AcquireJob leases the next eligible job from queue, or from any queue when
+// queue is empty.
+func (s *SQLiteStorage) AcquireJob(ctx context.Context, queue string) (*models.Job, error) {
+	return firstJob(s.AcquireJobs(ctx, queue, 1))
+}
+
+// AcquireJobs leases up to n eligible jobs from queue (any queue when empty)
+// in a single transaction, each under its own lease token.
+func (s *SQLiteStorage) AcquireJobs(ctx context.Context, queue string, n int) ([]models.Job, error) {
+	// The queue predicate is only added when set so the planner can use
+	// idx_jobs_queue_acquire; otherwise idx_jobs_acquire applies.
+	if queue == "" { return s.acquire(ctx, `state='pending'`, nil, n) }
+	return s.acquire(ctx, `queue=? AND state='pending'`, []any{queue}, n)
+}
+
+// AcquireJobByID leases the job id if it is eligible to run now, and returns
+// nil otherwise (not pending, not due yet, or leased by someone else).
+func (s *SQLiteStorage) AcquireJobByID(ctx context.Context, id string) (*models.Job, error) {
+	return firstJob(s.acquire(ctx, `id=? AND state='pending'`, []any{id}, 1))
+}
+
+// acquire leases up to n eligible jobs matching where, highest priority
+// first and oldest first within a priority.
+func (s *SQLiteStorage) acquire(ctx context.Context, where string, args []any, n int) ([]models.Job, error) {
+	// BEGIN IMMEDIATE (see sqliteParams): workers queue for the write lock here
+	// rather than both reading the same rows and one failing on UPDATE.
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil { return nil, err }
 	defer func() { _ = tx.Rollback() }()
 
-	row := tx.QueryRowContext(ctx, `
-SELECT id, command, state, attempts, max_retries, error, next_retry, created_at, updated_at, timeout_seconds
+	jobs, err := queryJobs(ctx, tx, `
+SELECT `+jobCols+`
 FROM jobs
-WHERE state='pending' AND (next_retry IS NULL OR next_retry <= CURRENT_TIMESTAMP)
-ORDER BY created_at ASC LIMIT 1`)
-	var j models.Job
-	if err := row.Scan(&j.ID, &j.Command, &j.State, &j.Attempts, &j.MaxRetries, &j.Error, &j.NextRetry, &j.CreatedAt, &j.UpdatedAt, &j.TimeoutSeconds); err != nil {
-		if errors.Is(err, sql.ErrNoRows) { return nil, nil }
-		return nil, err
-	}
+WHERE `+where+`
+  AND (next_retry IS NULL OR next_retry <= CURRENT_TIMESTAMP)
+  AND (run_at IS NULL OR run_at <= CURRENT_TIMESTAMP)
+ORDER BY priority DESC, created_at ASC LIMIT ?`, append(args, n)...)
+	if err != nil || len(jobs) == 0 { return nil, err }
 
-	workerID := uuid.NewString()
-	leaseUntil := time.Now().UTC().Add(time.Duration(j.TimeoutSeconds+10) * time.Second)
-
-	res, err := tx.ExecContext(ctx, `
+	now := time.Now().UTC()
+	for i := range jobs {
+		j := &jobs[i]
+		workerID := uuid.NewString()
+		leaseUntil := now.Add(time.Duration(j.TimeoutSeconds+10) * time.Second)
+		if _, err := tx.ExecContext(ctx, `
 UPDATE jobs
 SET state='processing', worker_id=?, lease_until=?, updated_at=CURRENT_TIMESTAMP
-WHERE id=? AND state='pending'`, workerID, leaseUntil, j.ID)
-	if err != nil { return nil, err }
-	if n, _ := res.RowsAffected(); n != 1 { return nil, nil }
-
+WHERE id=? AND state='pending'`, workerID, leaseUntil, j.ID); err != nil {
+			return nil, err
+		}
+		j.State, j.UpdatedAt = models.StateProcessing, now
+		j.WorkerID, j.LeaseUntil = &workerID, &leaseUntil
+	}
 	if err := tx.Commit(); err != nil { return nil, err }
-	j.State, j.UpdatedAt = models.StateProcessing, time.Now().UTC()
-	j.WorkerID, j.LeaseUntil = &workerID, &leaseUntil
-	return &j, nil
+	return jobs, nil
 }
 
+// firstJob adapts AcquireJobs(ctx, queue, 1) to AcquireJob.
+func firstJob(jobs []models.Job, err error) (*models.Job, error) {
+	if err != nil || len(jobs) == 0 { return nil, err }
+	return &jobs[0], nil
+}
+
+// SetPriority changes the priority of a job that has not started yet.
+func (s *SQLiteStorage) SetPriority(ctx context.Context, id string, priority int) error {
+	res, err := s.db.ExecContext(ctx, `
+UPDATE jobs SET priority=?, updated_at=CURRENT_TIMESTAMP WHERE id=? AND state='pending'`, priority, id)
+	if err != nil { return err }
+	if n, _ := res.RowsAffected(); n != 1 {
+		return fmt.Errorf("job %s not found or not pending", id)
+	}
+	return nil
+}
+
+// RequeueExpiredLeases returns jobs whose worker stopped heartbeating to
+// pending, or straight to cancelled if a cancel was requested meanwhile. The
+// abandoned attempts are closed so the job's history stays accurate.
 func (s *SQLiteStorage) RequeueExpiredLeases(ctx context.Context) error {
-	_, err := s.db.ExecContext(ctx, `
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil { return err }
+	defer func() { _ = tx.Rollback() }()
+
+	const expired = `state='processing' AND lease_until IS NOT NULL AND lease_until <= CURRENT_TIMESTAMP`
+	if _, err := tx.ExecContext(ctx, `
+UPDATE job_attempts SET ended_at=?, error='lease expired'
+WHERE ended_at IS NULL AND lease_id IN (SELECT worker_id FROM jobs WHERE `+expired+`)`, time.Now().UTC()); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `
 UPDATE jobs
-SET state='pending', worker_id=NULL, lease_until=NULL, updated_at=CURRENT_TIMESTAMP
-WHERE state='processing' AND lease_until IS NOT NULL AND lease_until <= CURRENT_TIMESTAMP`)
-	return err
+SET state=CASE WHEN cancel_requested=1 THEN 'cancelled' ELSE 'pending' END,
+    worker_id=NULL, lease_until=NULL, updated_at=CURRENT_TIMESTAMP
+WHERE `+expired)
+	if err != nil { return err }
+	if err := tx.Commit(); err != nil { return err }
+	if n, _ := res.RowsAffected(); n > 0 { s.jobsChanged() }
+	return nil
 }
\ No newline at end of file
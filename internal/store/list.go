@@ -2,31 +2,34 @@ package store
 
 import (
 	"context"
-	"database/sql"
 
 	"github.com/shashidhxr/queueCTL/pkg/models"
 )
 
-func (s *SQLiteStorage) ListJobs(ctx context.Context, state models.JobState, limit int) ([]models.Job, error) {
-	if limit <= 0 { limit = 50 }
-	rows, err := s.db.QueryContext(ctx, `
-SELECT id, command, state, attempts, max_retries, error, next_retry, created_at, updated_at
+// JobFilter narrows ListJobs. Zero values mean "no restriction".
+type JobFilter struct {
+	State models.JobState // "scheduled" selects pending jobs with a future run_at
+	Queue string
+	Limit int
+}
+
+func (s *SQLiteStorage) ListJobs(ctx context.Context, f JobFilter) ([]models.Job, error) {
+	if f.Limit <= 0 { f.Limit = 50 }
+	jobs, err := queryJobs(ctx, s.db, `
+SELECT `+jobCols+`
 FROM jobs
-WHERE (? = '' OR state = ?)
+WHERE (? = '' OR state = ? OR (? = 'scheduled' AND state = 'pending' AND run_at > CURRENT_TIMESTAMP))
+  AND (? = '' OR queue = ?)
 ORDER BY created_at DESC
-LIMIT ?`, state, state, limit)
+LIMIT ?`, f.State, f.State, f.State, f.Queue, f.Queue, f.Limit)
 	if err != nil { return nil, err }
-	defer rows.Close()
 
-	var out []models.Job
-	for rows.Next() {
-		var j models.Job
-		var next sql.NullTime
-		if err := rows.Scan(&j.ID, &j.Command, &j.State, &j.Attempts, &j.MaxRetries, &j.Error, &next, &j.CreatedAt, &j.UpdatedAt); err != nil {
-		 return nil, err
-		}
-		if next.Valid { t := next.Time; j.NextRetry = &t }
-		out = append(out, j)
+	ids := make([]string, len(jobs))
+	for i := range jobs { ids[i] = jobs[i].ID }
+	last, err := s.LastAttempts(ctx, ids)
+	if err != nil { return nil, err }
+	for i := range jobs {
+		if a, ok := last[jobs[i].ID]; ok { jobs[i].LastAttempt = &a }
 	}
-	return out, rows.Err()
+	return jobs, nil
 }
@@ -2,32 +2,75 @@ package store
 
 import (
 	"context"
+	"database/sql"
+	"strings"
 	"time"
 )
 
 type LogLine struct {
-	TS     time.Time
-	Stream string
-	Chunk  string
+	ID      int64     `json:"id"`
+	TS      time.Time `json:"ts"`
+	Attempt int       `json:"attempt,omitempty"` // 0 for logs written before attempts were tracked
+	Stream  string    `json:"stream"`
+	Chunk   string    `json:"chunk"`
 }
 
-func (s *SQLiteStorage) AppendLog(ctx context.Context, jobID, stream, chunk string) error {
+// LogFilter narrows GetLogs. Zero values don't filter.
+type LogFilter struct {
+	AfterID int64     // only rows with a larger id, for following a running job
+	Stream  string    // stdout or stderr
+	Since   time.Time
+	Attempt int
+	Limit   int
+}
+
+func (s *SQLiteStorage) AppendLog(ctx context.Context, jobID string, attempt int, stream, chunk string) error {
 	_, err := s.db.ExecContext(ctx, `
-INSERT INTO job_logs(job_id, ts, stream, chunk) VALUES(?, CURRENT_TIMESTAMP, ?, ?)`, jobID, stream, chunk)
+INSERT INTO job_logs(job_id, ts, attempt, stream, chunk) VALUES(?, CURRENT_TIMESTAMP, ?, ?, ?)`, jobID, attempt, stream, chunk)
 	return err
 }
 
-func (s *SQLiteStorage) GetLogs(ctx context.Context, jobID string, limit int) ([]LogLine, error) {
-	if limit <= 0 { limit = 200 }
+// GetLogs returns a job's log chunks matching f in the order they were written.
+func (s *SQLiteStorage) GetLogs(ctx context.Context, jobID string, f LogFilter) ([]LogLine, error) {
+	if f.Limit <= 0 { f.Limit = 200 }
+	where := []string{"job_id = ?", "id > ?"}
+	args := []any{jobID, f.AfterID}
+	if f.Stream != "" {
+		where = append(where, "stream = ?")
+		args = append(args, f.Stream)
+	}
+	if !f.Since.IsZero() {
+		// ts is written by CURRENT_TIMESTAMP, so compare in the same format
+		where = append(where, "ts >= ?")
+		args = append(args, f.Since.UTC().Format("2006-01-02 15:04:05"))
+	}
+	if f.Attempt > 0 {
+		where = append(where, "attempt = ?")
+		args = append(args, f.Attempt)
+	}
 	rows, err := s.db.QueryContext(ctx, `
-SELECT ts, stream, chunk FROM job_logs WHERE job_id = ? ORDER BY ts ASC LIMIT ?`, jobID, limit)
+SELECT id, ts, attempt, stream, chunk FROM job_logs
+WHERE `+strings.Join(where, " AND ")+` ORDER BY id ASC LIMIT ?`, append(args, f.Limit)...)
 	if err != nil { return nil, err }
-	defer rows.Close()
+	return scanLogs(rows)
+}
+
+// TailLogs returns the last n log chunks of a job, oldest first.
+func (s *SQLiteStorage) TailLogs(ctx context.Context, jobID string, n int) ([]LogLine, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT id, ts, attempt, stream, chunk FROM (
+  SELECT id, ts, attempt, stream, chunk FROM job_logs WHERE job_id = ? ORDER BY id DESC LIMIT ?
+) ORDER BY id ASC`, jobID, n)
+	if err != nil { return nil, err }
+	return scanLogs(rows)
+}
 
+func scanLogs(rows *sql.Rows) ([]LogLine, error) {
+	defer rows.Close()
 	var out []LogLine
 	for rows.Next() {
 		var l LogLine
-		if err := rows.Scan(&l.TS, &l.Stream, &l.Chunk); err != nil { return nil, err }
+		if err := rows.Scan(&l.ID, &l.TS, &l.Attempt, &l.Stream, &l.Chunk); err != nil { return nil, err }
 		out = append(out, l)
 	}
 	return out, rows.Err()
@@ -0,0 +1,401 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shashidhxr/queueCTL/pkg/models"
+)
+
+// MemoryStorage is a Store that keeps everything in process memory, for tests
+// and ephemeral runs (--db memory://). It follows SQLiteStorage's semantics,
+// including lease fencing, and is safe for concurrent use; nothing survives
+// Close or the process.
+type MemoryStorage struct {
+	*notifier // Waker
+
+	mu sync.Mutex
+
+	jobs      map[string]*memJob
+	seq       int64 // insertion order, breaks created_at ties like SQLite's rowid
+	attempts  []models.Attempt
+	logs      []LogLine
+	logJobs   []string // job id of each entry in logs
+	schedules map[string]*models.Schedule
+	queues    map[string]models.QueueSettings
+	config    map[string]string
+}
+
+type memJob struct {
+	models.Job
+	seq int64
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		notifier:  newNotifier(),
+		jobs:      map[string]*memJob{},
+		schedules: map[string]*models.Schedule{},
+		queues:    map[string]models.QueueSettings{},
+		config:    map[string]string{},
+	}
+}
+
+func (s *MemoryStorage) Close() error { return nil }
+
+// cloneJob copies j so callers never share pointers with the stored job.
+func cloneJob(j models.Job) models.Job {
+	j.NextRetry = cloneTime(j.NextRetry)
+	j.RunAt = cloneTime(j.RunAt)
+	j.LeaseUntil = cloneTime(j.LeaseUntil)
+	if j.WorkerID != nil { v := *j.WorkerID; j.WorkerID = &v }
+	if j.RetryPolicy != nil { v := *j.RetryPolicy; j.RetryPolicy = &v }
+	if j.Backoff != nil { v := *j.Backoff; j.Backoff = &v }
+	j.LastAttempt = nil
+	return j
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil { return nil }
+	v := *t
+	return &v
+}
+
+func (s *MemoryStorage) insertJob(j *models.Job) bool {
+	if _, ok := s.jobs[j.ID]; ok { return false }
+	s.seq++
+	s.jobs[j.ID] = &memJob{Job: cloneJob(*j), seq: s.seq}
+	return true
+}
+
+func (s *MemoryStorage) SaveJob(ctx context.Context, j *models.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	if j.State == "" { j.State = models.StatePending }
+	if j.Queue == "" { j.Queue = models.DefaultQueue }
+	j.CreatedAt, j.UpdatedAt = now, now
+	if !s.insertJob(j) { return fmt.Errorf("job %s already exists", j.ID) }
+	s.notify()
+	return nil
+}
+
+func (s *MemoryStorage) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok { return nil, sql.ErrNoRows }
+	out := cloneJob(j.Job)
+	return &out, nil
+}
+
+func (s *MemoryStorage) UpdateJobState(ctx context.Context, id string, state models.JobState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if j, ok := s.jobs[id]; ok {
+		j.State, j.UpdatedAt = state, time.Now().UTC()
+	}
+	return nil
+}
+
+// scheduled reports whether a pending job is waiting for its run_at, the
+// state status and list report as "scheduled".
+func (j *memJob) scheduled(now time.Time) bool {
+	return j.State == models.StatePending && j.RunAt != nil && j.RunAt.After(now)
+}
+
+// sorted returns the jobs matching keep, ordered by less.
+func (s *MemoryStorage) sorted(keep func(*memJob) bool, less func(a, b *memJob) bool) []*memJob {
+	var out []*memJob
+	for _, j := range s.jobs {
+		if keep(j) { out = append(out, j) }
+	}
+	sort.Slice(out, func(a, b int) bool { return less(out[a], out[b]) })
+	return out
+}
+
+func newestFirst(a, b *memJob) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) { return a.CreatedAt.After(b.CreatedAt) }
+	return a.seq > b.seq
+}
+
+func (s *MemoryStorage) ListJobs(ctx context.Context, f JobFilter) ([]models.Job, error) {
+	if f.Limit <= 0 { f.Limit = 50 }
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	matched := s.sorted(func(j *memJob) bool {
+		stateOK := f.State == "" || j.State == f.State || (f.State == models.StateScheduled && j.scheduled(now))
+		return stateOK && (f.Queue == "" || j.Queue == f.Queue)
+	}, newestFirst)
+
+	var out []models.Job
+	for i, j := range matched {
+		if i == f.Limit { break }
+		job := cloneJob(j.Job)
+		if a, ok := s.lastAttempt(j.ID); ok { job.LastAttempt = &a }
+		out = append(out, job)
+	}
+	return out, nil
+}
+
+// AcquireJob leases the next eligible job from queue, or from any queue when
+// queue is empty.
+func (s *MemoryStorage) AcquireJob(ctx context.Context, queue string) (*models.Job, error) {
+	return firstJob(s.AcquireJobs(ctx, queue, 1))
+}
+
+// AcquireJobs leases up to n eligible jobs from queue, or from any queue when
+// queue is empty.
+func (s *MemoryStorage) AcquireJobs(ctx context.Context, queue string, n int) ([]models.Job, error) {
+	return s.acquire(func(j *memJob) bool { return queue == "" || j.Queue == queue }, n), nil
+}
+
+// AcquireJobByID leases the job id if it is eligible to run now, and returns
+// nil otherwise (not pending, not due yet, or leased by someone else).
+func (s *MemoryStorage) AcquireJobByID(ctx context.Context, id string) (*models.Job, error) {
+	return firstJob(s.acquire(func(j *memJob) bool { return j.ID == id }, 1), nil)
+}
+
+// acquire leases up to n eligible jobs that match, highest priority first and
+// oldest first within a priority.
+func (s *MemoryStorage) acquire(match func(*memJob) bool, n int) []models.Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	eligible := s.sorted(func(j *memJob) bool {
+		return j.State == models.StatePending && match(j) &&
+			(j.NextRetry == nil || !j.NextRetry.After(now)) && (j.RunAt == nil || !j.RunAt.After(now))
+	}, func(a, b *memJob) bool {
+		if a.Priority != b.Priority { return a.Priority > b.Priority }
+		if !a.CreatedAt.Equal(b.CreatedAt) { return a.CreatedAt.Before(b.CreatedAt) }
+		return a.seq < b.seq
+	})
+
+	var out []models.Job
+	for _, j := range eligible {
+		if len(out) == n { break }
+		workerID := uuid.NewString()
+		leaseUntil := now.Add(time.Duration(j.TimeoutSeconds+10) * time.Second)
+		j.State, j.UpdatedAt = models.StateProcessing, now
+		j.WorkerID, j.LeaseUntil = &workerID, &leaseUntil
+		out = append(out, cloneJob(j.Job))
+	}
+	return out
+}
+
+func (s *MemoryStorage) SetPriority(ctx context.Context, id string, priority int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok || j.State != models.StatePending {
+		return fmt.Errorf("job %s not found or not pending", id)
+	}
+	j.Priority, j.UpdatedAt = priority, time.Now().UTC()
+	return nil
+}
+
+func (s *MemoryStorage) CancelJob(ctx context.Context, id string) (models.JobState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok { return "", fmt.Errorf("job %s not found", id) }
+	state := j.State
+	switch state {
+	case models.StatePending:
+		j.State, j.Error, j.UpdatedAt = models.StateCancelled, "cancelled", time.Now().UTC()
+	case models.StateProcessing:
+		j.CancelRequested = true
+	default:
+		return state, fmt.Errorf("job %s is already %s", id, state)
+	}
+	return state, nil
+}
+
+func (s *MemoryStorage) CancelJobs(ctx context.Context, f JobFilter) (cancelled, requested int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	for _, j := range s.jobs {
+		if f.Queue != "" && j.Queue != f.Queue { continue }
+		switch {
+		case j.State == models.StatePending && (f.State == "" || f.State == models.StatePending):
+			j.State, j.Error, j.UpdatedAt = models.StateCancelled, "cancelled", now
+			cancelled++
+		case j.State == models.StateProcessing && !j.CancelRequested && (f.State == "" || f.State == models.StateProcessing):
+			j.CancelRequested = true
+			requested++
+		}
+	}
+	return cancelled, requested, nil
+}
+
+// leased returns the job if workerID still holds its lease; the caller must
+// hold s.mu.
+func (s *MemoryStorage) leased(id, workerID string) (*memJob, error) {
+	j, ok := s.jobs[id]
+	if !ok || j.State != models.StateProcessing || j.WorkerID == nil || *j.WorkerID != workerID {
+		return nil, ErrLeaseLost
+	}
+	return j, nil
+}
+
+func (s *MemoryStorage) RenewLease(ctx context.Context, id, workerID string, until time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, err := s.leased(id, workerID)
+	if err != nil { return false, err }
+	u := until.UTC()
+	j.LeaseUntil = &u
+	return j.CancelRequested, nil
+}
+
+func (s *MemoryStorage) RequeueExpiredLeases(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	for _, j := range s.jobs {
+		if j.State != models.StateProcessing || j.LeaseUntil == nil || j.LeaseUntil.After(now) { continue }
+		for i := range s.attempts {
+			a := &s.attempts[i]
+			if a.EndedAt == nil && j.WorkerID != nil && a.LeaseID == *j.WorkerID {
+				a.EndedAt, a.Error = cloneTime(&now), "lease expired"
+			}
+		}
+		j.State = models.StatePending
+		if j.CancelRequested { j.State = models.StateCancelled }
+		j.WorkerID, j.LeaseUntil, j.UpdatedAt = nil, nil, now
+		s.notify()
+	}
+	return nil
+}
+
+func (s *MemoryStorage) SetCompleted(ctx context.Context, id, workerID, result string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, err := s.leased(id, workerID)
+	if err != nil { return err }
+	j.State, j.Result, j.LeaseUntil, j.UpdatedAt = models.StateCompleted, result, nil, time.Now().UTC()
+	return nil
+}
+
+func (s *MemoryStorage) SetCancelled(ctx context.Context, id, workerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, err := s.leased(id, workerID)
+	if err != nil { return err }
+	j.State, j.Error, j.LeaseUntil, j.UpdatedAt = models.StateCancelled, "cancelled", nil, time.Now().UTC()
+	return nil
+}
+
+func (s *MemoryStorage) SetDead(ctx context.Context, j *models.Job, errStr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j.Attempts++
+	mj, err := s.leased(j.ID, leaseOwner(j))
+	if err != nil { return err }
+	mj.State, mj.Attempts, mj.Error = models.StateDead, j.Attempts, errStr
+	mj.LeaseUntil, mj.UpdatedAt = nil, time.Now().UTC()
+	return nil
+}
+
+func (s *MemoryStorage) FailOrSchedule(ctx context.Context, j *models.Job, backoff Backoff, errStr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j.Attempts++
+	mj, err := s.leased(j.ID, leaseOwner(j))
+	if err != nil { return err }
+	now := time.Now().UTC()
+	mj.Attempts, mj.Error, mj.LeaseUntil, mj.UpdatedAt = j.Attempts, errStr, nil, now
+	if j.Attempts > j.MaxRetries {
+		mj.State = models.StateDead
+		return nil
+	}
+	delay := retryDelay(j, backoff)
+	next := now.Add(delay)
+	j.RetryDelayMS = delay.Milliseconds()
+	mj.State, mj.NextRetry, mj.RetryDelayMS, mj.WorkerID = models.StatePending, &next, j.RetryDelayMS, nil
+	if mj.CancelRequested { mj.State = models.StateCancelled }
+	return nil
+}
+
+// match mirrors dlqWhere; LIKE in SQLite is case-insensitive for ASCII.
+func (f DLQFilter) match(j *memJob) bool {
+	return j.State == models.StateDead &&
+		(f.Queue == "" || j.Queue == f.Queue) &&
+		(f.ErrorContains == "" || strings.Contains(strings.ToLower(j.Error), strings.ToLower(f.ErrorContains))) &&
+		(f.OlderThan.IsZero() || !j.UpdatedAt.After(f.OlderThan))
+}
+
+func (s *MemoryStorage) GetDLQJobs(ctx context.Context, f DLQFilter) ([]models.Job, error) {
+	limit := f.Limit
+	if limit <= 0 { limit = 50 }
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	matched := s.sorted(f.match, func(a, b *memJob) bool {
+		if !a.UpdatedAt.Equal(b.UpdatedAt) { return a.UpdatedAt.After(b.UpdatedAt) }
+		return a.seq > b.seq
+	})
+	var out []models.Job
+	for i, j := range matched {
+		if i == limit { break }
+		out = append(out, cloneJob(j.Job))
+	}
+	return out, nil
+}
+
+// resetDead mirrors the SQL statement of the same name.
+func (j *memJob) resetDead(now time.Time) {
+	j.State, j.Attempts, j.Error, j.NextRetry, j.RetryDelayMS = models.StatePending, 0, "", nil, 0
+	j.WorkerID, j.LeaseUntil, j.UpdatedAt = nil, nil, now
+}
+
+func (s *MemoryStorage) RetryDLQJob(ctx context.Context, jobID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[jobID]
+	if !ok || j.State != models.StateDead { return fmt.Errorf("%w: %s", ErrNotInDLQ, jobID) }
+	j.resetDead(time.Now().UTC())
+	s.notify()
+	return nil
+}
+
+func (s *MemoryStorage) RetryDLQJobs(ctx context.Context, f DLQFilter) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now, n := time.Now().UTC(), 0
+	for _, j := range s.jobs {
+		if f.match(j) { j.resetDead(now); n++ }
+	}
+	if n > 0 { s.notify() }
+	return n, nil
+}
+
+func (s *MemoryStorage) PurgeDLQ(ctx context.Context, f DLQFilter) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	purged := map[string]bool{}
+	for id, j := range s.jobs {
+		if f.match(j) { purged[id] = true; delete(s.jobs, id) }
+	}
+	logs, logJobs := s.logs[:0], s.logJobs[:0]
+	for i, l := range s.logs {
+		if purged[s.logJobs[i]] { continue }
+		logs, logJobs = append(logs, l), append(logJobs, s.logJobs[i])
+	}
+	s.logs, s.logJobs = logs, logJobs
+	attempts := s.attempts[:0]
+	for _, a := range s.attempts {
+		if !purged[a.JobID] { attempts = append(attempts, a) }
+	}
+	s.attempts = attempts
+	return len(purged), nil
+}
+
+var _ Store = (*MemoryStorage)(nil)
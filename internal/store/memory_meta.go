@@ -0,0 +1,322 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shashidhxr/queueCTL/pkg/models"
+)
+
+func (s *MemoryStorage) StartAttempt(ctx context.Context, jobID, leaseID, worker string) (id int64, number int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	number = 1
+	for _, a := range s.attempts {
+		if a.JobID == jobID { number++ }
+	}
+	id = int64(len(s.attempts) + 1)
+	s.attempts = append(s.attempts, models.Attempt{
+		ID: id, JobID: jobID, Number: number, Worker: worker, LeaseID: leaseID, StartedAt: time.Now().UTC(),
+	})
+	return id, number, nil
+}
+
+func (s *MemoryStorage) FinishAttempt(ctx context.Context, id int64, u models.Usage, errStr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id < 1 || id > int64(len(s.attempts)) { return nil }
+	a := &s.attempts[id-1]
+	now := time.Now().UTC()
+	if u.ExitCode != nil { c := *u.ExitCode; u.ExitCode = &c }
+	a.EndedAt, a.Usage, a.Error = &now, u, errStr
+	return nil
+}
+
+func cloneAttempt(a models.Attempt) models.Attempt {
+	a.EndedAt = cloneTime(a.EndedAt)
+	if a.ExitCode != nil { c := *a.ExitCode; a.ExitCode = &c }
+	return a
+}
+
+func (s *MemoryStorage) GetAttempts(ctx context.Context, jobID string) ([]models.Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []models.Attempt
+	for _, a := range s.attempts {
+		if a.JobID == jobID { out = append(out, cloneAttempt(a)) }
+	}
+	return out, nil
+}
+
+func (s *MemoryStorage) LastAttempts(ctx context.Context, jobIDs []string) (map[string]models.Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := map[string]models.Attempt{}
+	for _, id := range jobIDs {
+		if a, ok := s.lastAttempt(id); ok { out[id] = a }
+	}
+	return out, nil
+}
+
+// lastAttempt returns jobID's latest attempt; the caller must hold s.mu.
+func (s *MemoryStorage) lastAttempt(jobID string) (models.Attempt, bool) {
+	for i := len(s.attempts) - 1; i >= 0; i-- {
+		if s.attempts[i].JobID == jobID { return cloneAttempt(s.attempts[i]), true }
+	}
+	return models.Attempt{}, false
+}
+
+func (s *MemoryStorage) AppendLog(ctx context.Context, jobID string, attempt int, stream, chunk string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logs = append(s.logs, LogLine{
+		ID: int64(len(s.logs) + 1), TS: time.Now().UTC(), Attempt: attempt, Stream: stream, Chunk: chunk,
+	})
+	s.logJobs = append(s.logJobs, jobID)
+	return nil
+}
+
+func (s *MemoryStorage) GetLogs(ctx context.Context, jobID string, f LogFilter) ([]LogLine, error) {
+	if f.Limit <= 0 { f.Limit = 200 }
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []LogLine
+	for i, l := range s.logs {
+		if len(out) == f.Limit { break }
+		if s.logJobs[i] != jobID || l.ID <= f.AfterID { continue }
+		if f.Stream != "" && l.Stream != f.Stream { continue }
+		if !f.Since.IsZero() && l.TS.Before(f.Since) { continue }
+		if f.Attempt > 0 && l.Attempt != f.Attempt { continue }
+		out = append(out, l)
+	}
+	return out, nil
+}
+
+func (s *MemoryStorage) TailLogs(ctx context.Context, jobID string, n int) ([]LogLine, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []LogLine
+	for i := len(s.logs) - 1; i >= 0 && len(out) < n; i-- {
+		if s.logJobs[i] == jobID { out = append(out, s.logs[i]) }
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 { out[i], out[j] = out[j], out[i] }
+	return out, nil
+}
+
+func cloneSchedule(sc models.Schedule) models.Schedule {
+	sc.LastRunAt = cloneTime(sc.LastRunAt)
+	return sc
+}
+
+func (s *MemoryStorage) SaveSchedule(ctx context.Context, sc *models.Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	if sc.CatchUp == "" { sc.CatchUp = models.CatchUpSkip }
+	if sc.Queue == "" { sc.Queue = models.DefaultQueue }
+	sc.CreatedAt, sc.UpdatedAt = now, now
+	sc.NextRunAt = sc.NextRunAt.UTC()
+	if _, ok := s.schedules[sc.ID]; ok { return fmt.Errorf("schedule %s already exists", sc.ID) }
+	c := cloneSchedule(*sc)
+	s.schedules[sc.ID] = &c
+	return nil
+}
+
+func (s *MemoryStorage) GetSchedule(ctx context.Context, id string) (*models.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sc, ok := s.schedules[id]
+	if !ok { return nil, fmt.Errorf("%w: %s", ErrScheduleNotFound, id) }
+	c := cloneSchedule(*sc)
+	return &c, nil
+}
+
+// schedulesBy returns the schedules matching keep, ordered by key.
+func (s *MemoryStorage) schedulesBy(keep func(*models.Schedule) bool, key func(*models.Schedule) time.Time) []models.Schedule {
+	var out []models.Schedule
+	for _, sc := range s.schedules {
+		if keep(sc) { out = append(out, cloneSchedule(*sc)) }
+	}
+	sort.Slice(out, func(a, b int) bool { return key(&out[a]).Before(key(&out[b])) })
+	return out
+}
+
+func (s *MemoryStorage) ListSchedules(ctx context.Context) ([]models.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.schedulesBy(func(*models.Schedule) bool { return true },
+		func(sc *models.Schedule) time.Time { return sc.CreatedAt }), nil
+}
+
+func (s *MemoryStorage) DueSchedules(ctx context.Context, now time.Time) ([]models.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.schedulesBy(func(sc *models.Schedule) bool { return !sc.Paused && !sc.NextRunAt.After(now) },
+		func(sc *models.Schedule) time.Time { return sc.NextRunAt }), nil
+}
+
+// updateSchedule applies fn to schedule id under the lock.
+func (s *MemoryStorage) updateSchedule(id string, fn func(*models.Schedule)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sc, ok := s.schedules[id]
+	if !ok { return fmt.Errorf("%w: %s", ErrScheduleNotFound, id) }
+	fn(sc)
+	sc.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (s *MemoryStorage) PauseSchedule(ctx context.Context, id string) error {
+	return s.updateSchedule(id, func(sc *models.Schedule) { sc.Paused = true })
+}
+
+func (s *MemoryStorage) ResumeSchedule(ctx context.Context, id string, next time.Time) error {
+	return s.updateSchedule(id, func(sc *models.Schedule) { sc.Paused, sc.NextRunAt = false, next.UTC() })
+}
+
+func (s *MemoryStorage) DeleteSchedule(ctx context.Context, id string) error {
+	return s.updateSchedule(id, func(*models.Schedule) { delete(s.schedules, id) })
+}
+
+// FireSchedule has the same compare-and-swap semantics as SQLiteStorage's.
+func (s *MemoryStorage) FireSchedule(ctx context.Context, sc *models.Schedule, next time.Time, jobs []*models.Job) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cur, ok := s.schedules[sc.ID]
+	if !ok || cur.Paused || !cur.NextRunAt.Equal(sc.NextRunAt) { return false, nil }
+
+	now := time.Now().UTC()
+	cur.NextRunAt, cur.UpdatedAt = next.UTC(), now
+	if len(jobs) > 0 { cur.LastRunAt = &now }
+	for _, j := range jobs {
+		if j.State == "" { j.State = models.StatePending }
+		if j.Queue == "" { j.Queue = models.DefaultQueue }
+		j.CreatedAt, j.UpdatedAt = now, now
+		s.insertJob(j) // a replayed tick is a no-op
+	}
+	if len(jobs) > 0 { s.notify() }
+	return true, nil
+}
+
+func cloneQueueSettings(q models.QueueSettings) models.QueueSettings {
+	if q.RetryPolicy != nil { v := *q.RetryPolicy; q.RetryPolicy = &v }
+	if q.Backoff != nil { v := *q.Backoff; q.Backoff = &v }
+	return q
+}
+
+func (s *MemoryStorage) GetQueueSettings(ctx context.Context, name string) (*models.QueueSettings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q, ok := s.queues[name]
+	if !ok { return &models.QueueSettings{Name: name}, nil }
+	q = cloneQueueSettings(q)
+	return &q, nil
+}
+
+func (s *MemoryStorage) ListQueueSettings(ctx context.Context) ([]models.QueueSettings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []models.QueueSettings
+	for _, q := range s.queues { out = append(out, cloneQueueSettings(q)) }
+	sort.Slice(out, func(a, b int) bool { return out[a].Name < out[b].Name })
+	return out, nil
+}
+
+func (s *MemoryStorage) SaveQueueSettings(ctx context.Context, q *models.QueueSettings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q.UpdatedAt = time.Now().UTC()
+	s.queues[q.Name] = cloneQueueSettings(*q)
+	return nil
+}
+
+func (s *MemoryStorage) DeleteQueueSettings(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.queues, name)
+	return nil
+}
+
+func (s *MemoryStorage) GetConfigValues(ctx context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]string, len(s.config))
+	for k, v := range s.config { out[k] = v }
+	return out, nil
+}
+
+func (s *MemoryStorage) SetConfig(ctx context.Context, key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config[key] = value
+	return nil
+}
+
+// reportedState is the state GetJobStats counts j under.
+func (j *memJob) reportedState(now time.Time) models.JobState {
+	if j.scheduled(now) { return models.StateScheduled }
+	return j.State
+}
+
+func (s *MemoryStorage) GetJobStats(ctx context.Context) (map[models.JobState]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := map[models.JobState]int{
+		models.StatePending: 0, models.StateProcessing: 0,
+		models.StateCompleted: 0, models.StateFailed: 0, models.StateDead: 0,
+		models.StateScheduled: 0, models.StateCancelled: 0,
+	}
+	now := time.Now().UTC()
+	for _, j := range s.jobs { out[j.reportedState(now)]++ }
+	return out, nil
+}
+
+func (s *MemoryStorage) GetQueueStats(ctx context.Context) (map[string]map[models.JobState]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := map[string]map[models.JobState]int{}
+	now := time.Now().UTC()
+	for _, j := range s.jobs {
+		if out[j.Queue] == nil { out[j.Queue] = map[models.JobState]int{} }
+		out[j.Queue][j.reportedState(now)]++
+	}
+	return out, nil
+}
+
+// GetAttemptStats classifies attempts the same way as the SQL version.
+func (s *MemoryStorage) GetAttemptStats(ctx context.Context, since time.Time) (map[string]AttemptStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := map[string]AttemptStats{}
+	wall := map[string]int64{}
+	for _, a := range s.attempts {
+		if a.EndedAt == nil || a.EndedAt.Before(since) { continue }
+		j, ok := s.jobs[a.JobID]
+		if !ok { continue }
+		st := out[j.Queue]
+		st.Attempts++
+		timedOut := strings.HasPrefix(a.Error, "timeout after")
+		switch {
+		case a.ExitCode != nil && *a.ExitCode == 0:
+			st.Succeeded++
+		case a.ExitCode != nil && *a.ExitCode > 0:
+			st.Failed++
+		}
+		if timedOut { st.TimedOut++ }
+		if a.Signal != "" && !timedOut && a.Error != "cancelled" && a.Error != ErrLeaseLost.Error() { st.Killed++ }
+		wall[j.Queue] += a.WallMS
+		st.MaxWallMS = max(st.MaxWallMS, a.WallMS)
+		st.UserMS += a.UserMS
+		st.SysMS += a.SysMS
+		st.MaxRSSKB = max(st.MaxRSSKB, a.MaxRSSKB)
+		out[j.Queue] = st
+	}
+	for q, st := range out {
+		st.AvgWallMS = wall[q] / int64(st.Attempts)
+		out[q] = st
+	}
+	return out, nil
+}
@@ -0,0 +1,223 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// ErrSchemaTooNew is returned when the database has migrations this binary
+// doesn't know about, i.e. it was upgraded by a newer queuectl.
+var ErrSchemaTooNew = errors.New("database schema is newer than this binary")
+
+// Migration is one numbered schema change, embedded in the binary from
+// migrations/<dialect>/NNNN_name.up.sql and the matching .down.sql.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string // empty if the migration cannot be rolled back
+}
+
+// MigrationState is a migration known to this binary and/or recorded in the
+// database.
+type MigrationState struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	Unknown   bool       `json:"unknown,omitempty"` // applied by a newer binary
+}
+
+// Migrator is implemented by stores with a versioned schema.
+type Migrator interface {
+	MigrationStatus(ctx context.Context) ([]MigrationState, error)
+	// SchemaVersion returns the database's schema version and the latest
+	// version this binary can migrate to.
+	SchemaVersion(ctx context.Context) (current, latest int, err error)
+	// MigrateTo applies or rolls back migrations until the schema is at version.
+	MigrateTo(ctx context.Context, version int) error
+}
+
+// loadMigrations reads the embedded migrations for dialect, oldest first.
+// The files are part of the binary, so a malformed set is a build error and
+// panics.
+func loadMigrations(dialect string) []Migration {
+	dir := path.Join("migrations", dialect)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil { panic(err) }
+
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		base, isUp := strings.CutSuffix(e.Name(), ".up.sql")
+		if !isUp {
+			var ok bool
+			if base, ok = strings.CutSuffix(e.Name(), ".down.sql"); !ok { continue }
+		}
+		num, name, _ := strings.Cut(base, "_")
+		v, err := strconv.Atoi(num)
+		if err != nil || v < 1 { panic(fmt.Sprintf("store: bad migration file name %s/%s", dir, e.Name())) }
+		body, err := fs.ReadFile(migrationFiles, path.Join(dir, e.Name()))
+		if err != nil { panic(err) }
+
+		m := byVersion[v]
+		if m == nil { m = &Migration{Version: v, Name: name}; byVersion[v] = m }
+		if isUp { m.Up = string(body) } else { m.Down = string(body) }
+	}
+
+	out := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" { panic(fmt.Sprintf("store: migration %d in %s has no .up.sql", m.Version, dir)) }
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].Version < out[b].Version })
+	for i, m := range out {
+		if m.Version != i+1 { panic(fmt.Sprintf("store: migrations in %s skip version %d", dir, i+1)) }
+	}
+	return out
+}
+
+// migrator runs a dialect's migrations and records them in schema_migrations.
+// All steps of one MigrateTo run in a single transaction, so a failed
+// migration leaves the schema where it was.
+type migrator struct {
+	db         *sql.DB
+	migrations []Migration
+
+	createTable string // DDL for schema_migrations
+	tableExists string // counts tables named by its single ? parameter
+	lock        string // run first in the transaction to serialize migrators, if set
+	rebind      func(q string) string
+	// adoptLegacy upgrades a database created before schema_migrations
+	// existed to the point where migration 1 applies cleanly.
+	adoptLegacy func(ctx context.Context, tx *sql.Tx) error
+}
+
+func (m *migrator) latest() int { return len(m.migrations) }
+
+func (m *migrator) q(query string) string {
+	if m.rebind == nil { return query }
+	return m.rebind(query)
+}
+
+// applied returns when each recorded migration was applied, or nothing if
+// schema_migrations doesn't exist yet.
+func (m *migrator) applied(ctx context.Context, db rowQuerier) (map[int]MigrationState, error) {
+	var n int
+	if err := db.QueryRowContext(ctx, m.q(m.tableExists), "schema_migrations").Scan(&n); err != nil { return nil, err }
+	out := map[int]MigrationState{}
+	if n == 0 { return out, nil }
+
+	rows, err := db.QueryContext(ctx, `SELECT version, name, applied_at FROM schema_migrations`)
+	if err != nil { return nil, err }
+	defer rows.Close()
+	for rows.Next() {
+		var s MigrationState
+		var at time.Time
+		if err := rows.Scan(&s.Version, &s.Name, &at); err != nil { return nil, err }
+		s.AppliedAt = &at
+		out[s.Version] = s
+	}
+	return out, rows.Err()
+}
+
+func currentVersion(applied map[int]MigrationState) int {
+	v := 0
+	for n := range applied { v = max(v, n) }
+	return v
+}
+
+func (m *migrator) SchemaVersion(ctx context.Context) (current, latest int, err error) {
+	applied, err := m.applied(ctx, m.db)
+	if err != nil { return 0, 0, err }
+	return currentVersion(applied), m.latest(), nil
+}
+
+func (m *migrator) MigrationStatus(ctx context.Context) ([]MigrationState, error) {
+	applied, err := m.applied(ctx, m.db)
+	if err != nil { return nil, err }
+	var out []MigrationState
+	for _, mg := range m.migrations {
+		s := MigrationState{Version: mg.Version, Name: mg.Name}
+		if a, ok := applied[mg.Version]; ok { s.AppliedAt = a.AppliedAt }
+		out = append(out, s)
+	}
+	for v, a := range applied {
+		if v > m.latest() { a.Unknown = true; out = append(out, a) }
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].Version < out[b].Version })
+	return out, nil
+}
+
+func (m *migrator) MigrateTo(ctx context.Context, version int) error {
+	if version < 0 || version > m.latest() {
+		return fmt.Errorf("no schema version %d (this binary knows 0..%d)", version, m.latest())
+	}
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil { return err }
+	defer func() { _ = tx.Rollback() }()
+
+	if m.lock != "" {
+		if _, err := tx.ExecContext(ctx, m.lock); err != nil { return err }
+	}
+	applied, err := m.applied(ctx, tx)
+	if err != nil { return err }
+	if cur := currentVersion(applied); cur > m.latest() {
+		return fmt.Errorf("%w: database is at version %d, this binary knows up to %d", ErrSchemaTooNew, cur, m.latest())
+	}
+	if len(applied) == 0 && m.adoptLegacy != nil {
+		var jobs int
+		if err := tx.QueryRowContext(ctx, m.q(m.tableExists), "jobs").Scan(&jobs); err != nil { return err }
+		if jobs > 0 {
+			if err := m.adoptLegacy(ctx, tx); err != nil { return fmt.Errorf("upgrade unversioned database: %w", err) }
+		}
+	}
+	if _, err := tx.ExecContext(ctx, m.createTable); err != nil { return err }
+
+	for _, mg := range m.migrations {
+		if _, ok := applied[mg.Version]; ok || mg.Version > version { continue }
+		if _, err := tx.ExecContext(ctx, mg.Up); err != nil {
+			return fmt.Errorf("migration %d_%s: %w", mg.Version, mg.Name, err)
+		}
+		if _, err := tx.ExecContext(ctx, m.q(`INSERT INTO schema_migrations(version, name, applied_at) VALUES(?, ?, ?)`),
+			mg.Version, mg.Name, time.Now().UTC()); err != nil {
+			return err
+		}
+	}
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mg := m.migrations[i]
+		if _, ok := applied[mg.Version]; !ok || mg.Version <= version { continue }
+		if mg.Down == "" { return fmt.Errorf("migration %d_%s cannot be rolled back", mg.Version, mg.Name) }
+		if _, err := tx.ExecContext(ctx, mg.Down); err != nil {
+			return fmt.Errorf("rollback %d_%s: %w", mg.Version, mg.Name, err)
+		}
+		if _, err := tx.ExecContext(ctx, m.q(`DELETE FROM schema_migrations WHERE version = ?`), mg.Version); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// migrateUp brings a freshly opened store's schema up to date. A schema newer
+// than the binary is left alone: commands that only read may still work, and
+// workers refuse to start (see cmd/worker.go).
+func (m *migrator) migrateUp() error {
+	err := m.MigrateTo(context.Background(), m.latest())
+	if errors.Is(err, ErrSchemaTooNew) { return nil }
+	return err
+}
+
+var (
+	_ Migrator = (*SQLiteStorage)(nil)
+	_ Migrator = (*PostgresStorage)(nil)
+)
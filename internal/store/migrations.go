@@ -1,38 +1,85 @@
 package store
 
-func (s *SQLiteStorage) runMigrations() error {
-	schema := `
-CREATE TABLE IF NOT EXISTS jobs (
-  id TEXT PRIMARY KEY,
-  command TEXT NOT NULL,
-  state TEXT NOT NULL,
-  attempts INTEGER NOT NULL DEFAULT 0,
-  max_retries INTEGER NOT NULL DEFAULT 3,
-  error TEXT,
-  next_retry TIMESTAMP,
-  created_at TIMESTAMP NOT NULL,
-  updated_at TIMESTAMP NOT NULL,
-  timeout_seconds INTEGER NOT NULL DEFAULT 30,
-  worker_id TEXT,
-  lease_until TIMESTAMP
-);
-CREATE INDEX IF NOT EXISTS idx_jobs_state_next ON jobs(state, next_retry, created_at);
-CREATE INDEX IF NOT EXISTS idx_jobs_lease ON jobs(state, lease_until);
+import (
+	"context"
+	"database/sql"
+)
 
-CREATE TABLE IF NOT EXISTS config (
-  key TEXT PRIMARY KEY,
-  value TEXT NOT NULL
-);
+func newSQLiteMigrator(db *sql.DB) *migrator {
+	return &migrator{
+		db:         db,
+		migrations: loadMigrations("sqlite"),
+		createTable: `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+  version INTEGER PRIMARY KEY,
+  name TEXT NOT NULL,
+  applied_at TIMESTAMP NOT NULL
+)`,
+		tableExists: `SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?`,
+		adoptLegacy: adoptLegacySQLite,
+	}
+}
+
+// adoptLegacySQLite adds the columns that releases before versioned
+// migrations added on startup, so that 0001_initial (all IF NOT EXISTS) can
+// then be recorded as applied. It must never change: later schema changes go
+// in new migrations.
+func adoptLegacySQLite(ctx context.Context, tx *sql.Tx) error {
+	for _, t := range []struct {
+		table string
+		cols  map[string]string
+	}{
+		{"jobs", map[string]string{
+			"run_at":           "TIMESTAMP",
+			"priority":         "INTEGER NOT NULL DEFAULT 0",
+			"queue":            "TEXT NOT NULL DEFAULT 'default'",
+			"backoff_base":     "REAL NOT NULL DEFAULT 0",
+			"cancel_requested": "INTEGER NOT NULL DEFAULT 0",
+			"retry_policy":     "TEXT",
+			"backoff":          "TEXT",
+			"retry_delay_ms":   "INTEGER NOT NULL DEFAULT 0",
+		}},
+		{"job_logs", map[string]string{
+			"attempt": "INTEGER NOT NULL DEFAULT 0",
+		}},
+		{"job_attempts", map[string]string{
+			"signal":     "TEXT NOT NULL DEFAULT ''",
+			"wall_ms":    "INTEGER NOT NULL DEFAULT 0",
+			"user_ms":    "INTEGER NOT NULL DEFAULT 0",
+			"sys_ms":     "INTEGER NOT NULL DEFAULT 0",
+			"max_rss_kb": "INTEGER NOT NULL DEFAULT 0",
+		}},
+		{"queues", map[string]string{
+			"backoff": "TEXT",
+		}},
+		{"schedules", map[string]string{
+			"queue": "TEXT NOT NULL DEFAULT 'default'",
+		}},
+	} {
+		if err := ensureColumns(ctx, tx, t.table, t.cols); err != nil { return err }
+	}
+	return nil
+}
+
+// ensureColumns adds the missing cols to table, if the table exists.
+func ensureColumns(ctx context.Context, tx *sql.Tx, table string, cols map[string]string) error {
+	rows, err := tx.QueryContext(ctx, `SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil { return err }
+	have := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil { rows.Close(); return err }
+		have[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil { return err }
+	if len(have) == 0 { return nil } // created by 0001_initial
 
-CREATE TABLE IF NOT EXISTS job_logs (
-  id INTEGER PRIMARY KEY AUTOINCREMENT,
-  job_id TEXT NOT NULL,
-  ts TIMESTAMP NOT NULL,
-  stream TEXT NOT NULL,
-  chunk TEXT NOT NULL
-);
-CREATE INDEX IF NOT EXISTS idx_job_logs_job_ts ON job_logs(job_id, ts);
-`
-	_, err := s.db.Exec(schema)
-	return err
+	for name, decl := range cols {
+		if have[name] { continue }
+		if _, err := tx.ExecContext(ctx, `ALTER TABLE `+table+` ADD COLUMN `+name+` `+decl); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,97 @@
+-- Keep in step with migrations/sqlite; the Postgres schema differs only in types
+-- (TIMESTAMPTZ, BOOLEAN, BIGSERIAL) and the NOTIFY trigger that wakes idle workers.
+CREATE TABLE IF NOT EXISTS jobs (
+  id TEXT PRIMARY KEY,
+  command TEXT NOT NULL,
+  queue TEXT NOT NULL DEFAULT 'default',
+  state TEXT NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  max_retries INTEGER NOT NULL DEFAULT 3,
+  error TEXT NOT NULL DEFAULT '',
+  next_retry TIMESTAMPTZ,
+  run_at TIMESTAMPTZ,
+  priority INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL,
+  timeout_seconds INTEGER NOT NULL DEFAULT 30,
+  backoff_base DOUBLE PRECISION NOT NULL DEFAULT 0,
+  worker_id TEXT,
+  lease_until TIMESTAMPTZ,
+  cancel_requested BOOLEAN NOT NULL DEFAULT FALSE,
+  retry_policy TEXT,
+  backoff TEXT,
+  retry_delay_ms BIGINT NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_jobs_state_next ON jobs(state, next_retry, created_at);
+CREATE INDEX IF NOT EXISTS idx_jobs_lease ON jobs(state, lease_until);
+CREATE INDEX IF NOT EXISTS idx_jobs_acquire ON jobs(state, priority DESC, created_at);
+CREATE INDEX IF NOT EXISTS idx_jobs_queue_acquire ON jobs(queue, state, priority DESC, created_at);
+
+CREATE TABLE IF NOT EXISTS config (
+  key TEXT PRIMARY KEY,
+  value TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS job_logs (
+  id BIGSERIAL PRIMARY KEY,
+  job_id TEXT NOT NULL,
+  ts TIMESTAMPTZ NOT NULL,
+  attempt INTEGER NOT NULL DEFAULT 0,
+  stream TEXT NOT NULL,
+  chunk TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_job_logs_job_id ON job_logs(job_id, id);
+
+CREATE TABLE IF NOT EXISTS job_attempts (
+  id BIGSERIAL PRIMARY KEY,
+  job_id TEXT NOT NULL,
+  number INTEGER NOT NULL,
+  worker TEXT NOT NULL,
+  lease_id TEXT NOT NULL,
+  started_at TIMESTAMPTZ NOT NULL,
+  ended_at TIMESTAMPTZ,
+  exit_code INTEGER,
+  error TEXT NOT NULL DEFAULT '',
+  signal TEXT NOT NULL DEFAULT '',
+  wall_ms BIGINT NOT NULL DEFAULT 0,
+  user_ms BIGINT NOT NULL DEFAULT 0,
+  sys_ms BIGINT NOT NULL DEFAULT 0,
+  max_rss_kb BIGINT NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_job_attempts_job ON job_attempts(job_id, number);
+CREATE INDEX IF NOT EXISTS idx_job_attempts_open ON job_attempts(lease_id) WHERE ended_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS schedules (
+  id TEXT PRIMARY KEY,
+  spec TEXT NOT NULL,
+  command TEXT NOT NULL,
+  queue TEXT NOT NULL DEFAULT 'default',
+  max_retries INTEGER NOT NULL DEFAULT 3,
+  catch_up TEXT NOT NULL DEFAULT 'skip',
+  paused BOOLEAN NOT NULL DEFAULT FALSE,
+  next_run_at TIMESTAMPTZ NOT NULL,
+  last_run_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_schedules_due ON schedules(paused, next_run_at);
+
+CREATE TABLE IF NOT EXISTS queues (
+  name TEXT PRIMARY KEY,
+  retry_policy TEXT,
+  backoff TEXT,
+  updated_at TIMESTAMPTZ NOT NULL
+);
+
+-- Wake idle workers whenever a job is inserted or returns to pending.
+CREATE OR REPLACE FUNCTION queuectl_notify_job() RETURNS trigger AS $$
+BEGIN
+  IF NEW.state = 'pending' THEN
+    PERFORM pg_notify('queuectl_jobs', NEW.queue);
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS jobs_notify ON jobs;
+CREATE TRIGGER jobs_notify AFTER INSERT OR UPDATE OF state ON jobs
+  FOR EACH ROW EXECUTE FUNCTION queuectl_notify_job();
@@ -0,0 +1,2 @@
+-- Result a completed job reported (see QUEUECTL_RESULT_FILE), capped by max_result_bytes.
+ALTER TABLE jobs ADD COLUMN result TEXT;
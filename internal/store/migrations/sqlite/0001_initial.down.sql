@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS queues;
+DROP TABLE IF EXISTS schedules;
+DROP TABLE IF EXISTS job_attempts;
+DROP TABLE IF EXISTS job_logs;
+DROP TABLE IF EXISTS config;
+DROP TABLE IF EXISTS jobs;
@@ -0,0 +1,86 @@
+-- Schema as of the first versioned release. Databases created before
+-- schema_migrations existed are upgraded to this point first (see adoptLegacy),
+-- so every statement must stay idempotent.
+CREATE TABLE IF NOT EXISTS jobs (
+  id TEXT PRIMARY KEY,
+  command TEXT NOT NULL,
+  queue TEXT NOT NULL DEFAULT 'default',
+  state TEXT NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  max_retries INTEGER NOT NULL DEFAULT 3,
+  error TEXT,
+  next_retry TIMESTAMP,
+  run_at TIMESTAMP,
+  priority INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+  timeout_seconds INTEGER NOT NULL DEFAULT 30,
+  backoff_base REAL NOT NULL DEFAULT 0,
+  worker_id TEXT,
+  lease_until TIMESTAMP,
+  cancel_requested INTEGER NOT NULL DEFAULT 0,
+  retry_policy TEXT,
+  backoff TEXT,
+  retry_delay_ms INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_jobs_state_next ON jobs(state, next_retry, created_at);
+CREATE INDEX IF NOT EXISTS idx_jobs_lease ON jobs(state, lease_until);
+
+CREATE TABLE IF NOT EXISTS config (
+  key TEXT PRIMARY KEY,
+  value TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS job_logs (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  job_id TEXT NOT NULL,
+  ts TIMESTAMP NOT NULL,
+  attempt INTEGER NOT NULL DEFAULT 0,
+  stream TEXT NOT NULL,
+  chunk TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_job_logs_job_ts ON job_logs(job_id, ts);
+
+CREATE TABLE IF NOT EXISTS job_attempts (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  job_id TEXT NOT NULL,
+  number INTEGER NOT NULL,
+  worker TEXT NOT NULL,
+  lease_id TEXT NOT NULL,
+  started_at TIMESTAMP NOT NULL,
+  ended_at TIMESTAMP,
+  exit_code INTEGER,
+  error TEXT NOT NULL DEFAULT '',
+  signal TEXT NOT NULL DEFAULT '',
+  wall_ms INTEGER NOT NULL DEFAULT 0,
+  user_ms INTEGER NOT NULL DEFAULT 0,
+  sys_ms INTEGER NOT NULL DEFAULT 0,
+  max_rss_kb INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_job_attempts_job ON job_attempts(job_id, number);
+CREATE INDEX IF NOT EXISTS idx_job_attempts_open ON job_attempts(lease_id) WHERE ended_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS schedules (
+  id TEXT PRIMARY KEY,
+  spec TEXT NOT NULL,
+  command TEXT NOT NULL,
+  queue TEXT NOT NULL DEFAULT 'default',
+  max_retries INTEGER NOT NULL DEFAULT 3,
+  catch_up TEXT NOT NULL DEFAULT 'skip',
+  paused INTEGER NOT NULL DEFAULT 0,
+  next_run_at TIMESTAMP NOT NULL,
+  last_run_at TIMESTAMP,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_schedules_due ON schedules(paused, next_run_at);
+
+CREATE TABLE IF NOT EXISTS queues (
+  name TEXT PRIMARY KEY,
+  retry_policy TEXT,
+  backoff TEXT,
+  updated_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_jobs_acquire ON jobs(state, priority DESC, created_at);
+CREATE INDEX IF NOT EXISTS idx_jobs_queue_acquire ON jobs(queue, state, priority DESC, created_at);
+CREATE INDEX IF NOT EXISTS idx_job_logs_job_id ON job_logs(job_id, id);
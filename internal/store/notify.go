@@ -0,0 +1,25 @@
+package store
+
+// notifier implements Waker for stores whose writers live in this process:
+// notify wakes the worker's dispatcher, which leases for all its goroutines.
+// Wakeups coalesce, since one lease round picks up every runnable job.
+type notifier struct {
+	ch chan struct{}
+}
+
+func newNotifier() *notifier { return &notifier{ch: make(chan struct{}, 1)} }
+
+func (n *notifier) JobsAvailable() <-chan struct{} { return n.ch }
+
+func (n *notifier) notify() {
+	select {
+	case n.ch <- struct{}{}:
+	default: // a wakeup is already pending
+	}
+}
+
+var (
+	_ Waker = (*SQLiteStorage)(nil)
+	_ Waker = (*PostgresStorage)(nil)
+	_ Waker = (*MemoryStorage)(nil)
+)
@@ -0,0 +1,28 @@
+package store
+
+import "strings"
+
+// Waker is implemented by stores that can tell idle workers a job became
+// runnable instead of leaving them to poll.
+type Waker interface {
+	JobsAvailable() <-chan struct{}
+}
+
+// Open returns the Store for dsn: Postgres for postgres:// and postgresql://
+// URLs, a fresh in-memory store for memory://, otherwise a SQLite database at
+// that path. The schema is migrated to the latest version.
+func Open(dsn string) (Store, error) { return open(dsn, true) }
+
+// OpenUnmigrated is Open without the schema upgrade, for inspecting and
+// migrating the schema explicitly.
+func OpenUnmigrated(dsn string) (Store, error) { return open(dsn, false) }
+
+func open(dsn string, migrate bool) (Store, error) {
+	if strings.HasPrefix(dsn, "memory://") {
+		return NewMemoryStorage(), nil
+	}
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		return newPostgresStorage(dsn, migrate)
+	}
+	return newSQLiteStorage(dsn, migrate)
+}
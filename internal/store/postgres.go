@@ -0,0 +1,120 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PostgresStorage is the Store for multi-host deployments. It has the same
+// schema and semantics as SQLiteStorage; AcquireJob uses FOR UPDATE SKIP
+// LOCKED so workers on many hosts never block on each other, and a trigger
+// NOTIFYs idle workers whenever a job becomes runnable.
+type PostgresStorage struct {
+	db  *sql.DB
+	dsn string
+	*migrator
+
+	listenOnce sync.Once
+	listener   *pq.Listener
+	wake       chan struct{}
+}
+
+// jobsChannel is the LISTEN/NOTIFY channel the jobs trigger publishes on.
+const jobsChannel = "queuectl_jobs"
+
+// NewPostgresStorage connects to dsn and migrates its schema to the latest
+// version.
+func NewPostgresStorage(dsn string) (*PostgresStorage, error) {
+	return newPostgresStorage(dsn, true)
+}
+
+func newPostgresStorage(dsn string, migrate bool) (*PostgresStorage, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	s := &PostgresStorage{db: db, dsn: dsn, migrator: newPostgresMigrator(db)}
+	if migrate {
+		if err := s.migrateUp(); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// JobsAvailable returns a channel that receives when a job may have become
+// runnable on any host. The LISTEN connection is opened on first use; each
+// notification wakes at most one receiver, and bursts are coalesced.
+func (s *PostgresStorage) JobsAvailable() <-chan struct{} {
+	s.listenOnce.Do(func() {
+		s.wake = make(chan struct{}, 64)
+		l := pq.NewListener(s.dsn, time.Second, time.Minute, nil)
+		if err := l.Listen(jobsChannel); err != nil {
+			_ = l.Close()
+			return // workers keep polling
+		}
+		s.listener = l
+		go func() {
+			// A nil notification follows a reconnect, when some may have been
+			// missed; waking a worker is the right response either way.
+			for range l.Notify {
+				select {
+				case s.wake <- struct{}{}:
+				default:
+				}
+			}
+		}()
+	})
+	return s.wake
+}
+
+func (s *PostgresStorage) Close() error {
+	s.listenOnce.Do(func() {}) // no listener may start after this
+	if s.listener != nil {
+		_ = s.listener.Close()
+	}
+	return s.db.Close()
+}
+
+func newPostgresMigrator(db *sql.DB) *migrator {
+	return &migrator{
+		db:         db,
+		migrations: loadMigrations("postgres"),
+		createTable: `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+  version INTEGER PRIMARY KEY,
+  name TEXT NOT NULL,
+  applied_at TIMESTAMPTZ NOT NULL
+)`,
+		tableExists: `SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ?`,
+		// Serializes concurrent first starts, which would otherwise race on
+		// CREATE TABLE.
+		lock:   `SELECT pg_advisory_xact_lock(7346021)`,
+		rebind: pgRebind,
+	}
+}
+
+// pgRebind numbers the ? placeholders of a query shared with SQLite.
+func pgRebind(q string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range q {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
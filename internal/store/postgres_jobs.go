@@ -0,0 +1,307 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shashidhxr/queueCTL/pkg/models"
+)
+
+func (s *PostgresStorage) SaveJob(ctx context.Context, j *models.Job) error {
+	now := time.Now().UTC()
+	if j.State == "" {
+		j.State = models.StatePending
+	}
+	if j.Queue == "" {
+		j.Queue = models.DefaultQueue
+	}
+	j.CreatedAt, j.UpdatedAt = now, now
+	_, err := pgInsertJob(ctx, s.db, "", j)
+	return err
+}
+
+// pgInsertJob is insertJob for Postgres; onConflict is appended verbatim,
+// e.g. "ON CONFLICT (id) DO NOTHING".
+func pgInsertJob(ctx context.Context, ex execer, onConflict string, j *models.Job) (sql.Result, error) {
+	policy, err := nullableJSON(j.RetryPolicy)
+	if err != nil { return nil, err }
+	backoff, err := nullableJSON(j.Backoff)
+	if err != nil { return nil, err }
+	return ex.ExecContext(ctx, `
+INSERT INTO jobs (id, command, queue, state, attempts, max_retries, error, next_retry, run_at, priority, created_at, updated_at, timeout_seconds, backoff_base, retry_policy, backoff)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) `+onConflict,
+		j.ID, j.Command, j.Queue, j.State, j.Attempts, j.MaxRetries, j.Error, nullableTime(j.NextRetry), nullableTime(j.RunAt), j.Priority, j.CreatedAt, j.UpdatedAt, j.TimeoutSeconds, j.BackoffBase, policy, backoff)
+}
+
+func (s *PostgresStorage) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	return scanJob(s.db.QueryRowContext(ctx, `SELECT `+jobCols+` FROM jobs WHERE id = $1`, id))
+}
+
+func (s *PostgresStorage) UpdateJobState(ctx context.Context, id string, state models.JobState) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE jobs SET state=$1, updated_at=now() WHERE id=$2`, state, id)
+	return err
+}
+
+func (s *PostgresStorage) ListJobs(ctx context.Context, f JobFilter) ([]models.Job, error) {
+	if f.Limit <= 0 { f.Limit = 50 }
+	jobs, err := queryJobs(ctx, s.db, `
+SELECT `+jobCols+`
+FROM jobs
+WHERE ($1 = '' OR state = $1 OR ($1 = 'scheduled' AND state = 'pending' AND run_at > now()))
+  AND ($2 = '' OR queue = $2)
+ORDER BY created_at DESC
+LIMIT $3`, string(f.State), f.Queue, f.Limit)
+	if err != nil { return nil, err }
+
+	ids := make([]string, len(jobs))
+	for i := range jobs { ids[i] = jobs[i].ID }
+	last, err := s.LastAttempts(ctx, ids)
+	if err != nil { return nil, err }
+	for i := range jobs {
+		if a, ok := last[jobs[i].ID]; ok { jobs[i].LastAttempt = &a }
+	}
+	return jobs, nil
+}
+
+// AcquireJob leases the next eligible job from queue, or from any queue when
+// queue is empty.
+func (s *PostgresStorage) AcquireJob(ctx context.Context, queue string) (*models.Job, error) {
+	return firstJob(s.AcquireJobs(ctx, queue, 1))
+}
+
+// AcquireJobs leases up to n eligible jobs from queue, or from any queue when
+// queue is empty. SKIP LOCKED makes concurrent workers pick different rows
+// instead of queueing up behind the first one's row locks.
+func (s *PostgresStorage) AcquireJobs(ctx context.Context, queue string, n int) ([]models.Job, error) {
+	if queue == "" { return s.acquire(ctx, `state='pending'`, n) }
+	return s.acquire(ctx, `queue=$2 AND state='pending'`, n, queue)
+}
+
+// AcquireJobByID leases the job id if it is eligible to run now, and returns
+// nil otherwise (not pending, not due yet, or leased by someone else).
+func (s *PostgresStorage) AcquireJobByID(ctx context.Context, id string) (*models.Job, error) {
+	return firstJob(s.acquire(ctx, `id=$2 AND state='pending'`, 1, id))
+}
+
+// acquire leases up to n eligible jobs matching where, whose parameters start
+// at $2 ($1 is the limit).
+func (s *PostgresStorage) acquire(ctx context.Context, where string, n int, whereArgs ...any) ([]models.Job, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil { return nil, err }
+	defer func() { _ = tx.Rollback() }()
+
+	args := append([]any{n}, whereArgs...)
+	jobs, err := queryJobs(ctx, tx, `
+SELECT `+jobCols+`
+FROM jobs
+WHERE `+where+`
+  AND (next_retry IS NULL OR next_retry <= now())
+  AND (run_at IS NULL OR run_at <= now())
+ORDER BY priority DESC, created_at ASC LIMIT $1
+FOR UPDATE SKIP LOCKED`, args...)
+	if err != nil || len(jobs) == 0 { return nil, err }
+
+	now := time.Now().UTC()
+	for i := range jobs {
+		j := &jobs[i]
+		workerID := uuid.NewString()
+		leaseUntil := now.Add(time.Duration(j.TimeoutSeconds+10) * time.Second)
+		if _, err := tx.ExecContext(ctx, `
+UPDATE jobs SET state='processing', worker_id=$1, lease_until=$2, updated_at=now() WHERE id=$3`,
+			workerID, leaseUntil, j.ID); err != nil {
+			return nil, err
+		}
+		j.State, j.UpdatedAt = models.StateProcessing, now
+		j.WorkerID, j.LeaseUntil = &workerID, &leaseUntil
+	}
+	if err := tx.Commit(); err != nil { return nil, err }
+	return jobs, nil
+}
+
+func (s *PostgresStorage) SetPriority(ctx context.Context, id string, priority int) error {
+	res, err := s.db.ExecContext(ctx, `
+UPDATE jobs SET priority=$1, updated_at=now() WHERE id=$2 AND state='pending'`, priority, id)
+	if err != nil { return err }
+	if n, _ := res.RowsAffected(); n != 1 {
+		return fmt.Errorf("job %s not found or not pending", id)
+	}
+	return nil
+}
+
+func (s *PostgresStorage) CancelJob(ctx context.Context, id string) (models.JobState, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil { return "", err }
+	defer func() { _ = tx.Rollback() }()
+
+	var state models.JobState
+	if err := tx.QueryRowContext(ctx, `SELECT state FROM jobs WHERE id=$1 FOR UPDATE`, id).Scan(&state); err != nil {
+		if errors.Is(err, sql.ErrNoRows) { return "", fmt.Errorf("job %s not found", id) }
+		return "", err
+	}
+	switch state {
+	case models.StatePending:
+		_, err = tx.ExecContext(ctx, `
+UPDATE jobs SET state='cancelled', error='cancelled', updated_at=now() WHERE id=$1`, id)
+	case models.StateProcessing:
+		_, err = tx.ExecContext(ctx, `UPDATE jobs SET cancel_requested=TRUE WHERE id=$1`, id)
+	default:
+		return state, fmt.Errorf("job %s is already %s", id, state)
+	}
+	if err != nil { return "", err }
+	return state, tx.Commit()
+}
+
+func (s *PostgresStorage) CancelJobs(ctx context.Context, f JobFilter) (cancelled, requested int, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil { return 0, 0, err }
+	defer func() { _ = tx.Rollback() }()
+
+	if f.State == "" || f.State == models.StatePending {
+		res, err := tx.ExecContext(ctx, `
+UPDATE jobs SET state='cancelled', error='cancelled', updated_at=now()
+WHERE state='pending' AND ($1 = '' OR queue = $1)`, f.Queue)
+		if err != nil { return 0, 0, err }
+		n, _ := res.RowsAffected()
+		cancelled = int(n)
+	}
+	if f.State == "" || f.State == models.StateProcessing {
+		res, err := tx.ExecContext(ctx, `
+UPDATE jobs SET cancel_requested=TRUE
+WHERE state='processing' AND NOT cancel_requested AND ($1 = '' OR queue = $1)`, f.Queue)
+		if err != nil { return 0, 0, err }
+		n, _ := res.RowsAffected()
+		requested = int(n)
+	}
+	return cancelled, requested, tx.Commit()
+}
+
+// pgLeaseHeld is leaseHeld with the worker id as parameter $n.
+func pgLeaseHeld(n int) string {
+	return fmt.Sprintf(` AND state='processing' AND worker_id=$%d`, n)
+}
+
+func (s *PostgresStorage) RenewLease(ctx context.Context, id, workerID string, until time.Time) (bool, error) {
+	var cancel bool
+	err := s.db.QueryRowContext(ctx, `
+UPDATE jobs SET lease_until=$1 WHERE id=$2`+pgLeaseHeld(3)+` RETURNING cancel_requested`, until.UTC(), id, workerID).Scan(&cancel)
+	if errors.Is(err, sql.ErrNoRows) { return false, ErrLeaseLost }
+	return cancel, err
+}
+
+func (s *PostgresStorage) RequeueExpiredLeases(ctx context.Context) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil { return err }
+	defer func() { _ = tx.Rollback() }()
+
+	const expired = `state='processing' AND lease_until IS NOT NULL AND lease_until <= now()`
+	if _, err := tx.ExecContext(ctx, `
+UPDATE job_attempts SET ended_at=now(), error='lease expired'
+WHERE ended_at IS NULL AND lease_id IN (SELECT worker_id FROM jobs WHERE `+expired+`)`); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+UPDATE jobs
+SET state=CASE WHEN cancel_requested THEN 'cancelled' ELSE 'pending' END,
+    worker_id=NULL, lease_until=NULL, updated_at=now()
+WHERE `+expired); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *PostgresStorage) SetCompleted(ctx context.Context, id, workerID, result string) error {
+	return fenced(s.db.ExecContext(ctx, `
+UPDATE jobs SET state='completed', result=$1, lease_until=NULL, updated_at=now() WHERE id=$2`+pgLeaseHeld(3), nullableString(result), id, workerID))
+}
+
+func (s *PostgresStorage) SetCancelled(ctx context.Context, id, workerID string) error {
+	return fenced(s.db.ExecContext(ctx, `
+UPDATE jobs SET state='cancelled', error='cancelled', lease_until=NULL, updated_at=now() WHERE id=$1`+pgLeaseHeld(2), id, workerID))
+}
+
+func (s *PostgresStorage) SetDead(ctx context.Context, j *models.Job, errStr string) error {
+	j.Attempts++
+	return fenced(s.db.ExecContext(ctx, `
+UPDATE jobs SET state='dead', attempts=$1, error=$2, lease_until=NULL, updated_at=now() WHERE id=$3`+pgLeaseHeld(4),
+		j.Attempts, errStr, j.ID, leaseOwner(j)))
+}
+
+func (s *PostgresStorage) FailOrSchedule(ctx context.Context, j *models.Job, backoff Backoff, errStr string) error {
+	j.Attempts++
+	if j.Attempts > j.MaxRetries {
+		return fenced(s.db.ExecContext(ctx, `
+UPDATE jobs SET state='dead', attempts=$1, error=$2, lease_until=NULL, updated_at=now() WHERE id=$3`+pgLeaseHeld(4),
+			j.Attempts, errStr, j.ID, leaseOwner(j)))
+	}
+	delay := retryDelay(j, backoff)
+	next := time.Now().UTC().Add(delay)
+	j.RetryDelayMS = delay.Milliseconds()
+	return fenced(s.db.ExecContext(ctx, `
+UPDATE jobs SET state=CASE WHEN cancel_requested THEN 'cancelled' ELSE 'pending' END, attempts=$1, next_retry=$2, retry_delay_ms=$3, error=$4, worker_id=NULL, lease_until=NULL, updated_at=now() WHERE id=$5`+pgLeaseHeld(6),
+		j.Attempts, next, j.RetryDelayMS, errStr, j.ID, leaseOwner(j)))
+}
+
+// pgDLQWhere is dlqWhere with the filter as parameters $1..$3.
+const pgDLQWhere = `
+WHERE state='dead'
+  AND ($1 = '' OR queue = $1)
+  AND ($2 = '' OR error ILIKE '%' || $2 || '%' ESCAPE '\')
+  AND ($3::timestamptz IS NULL OR updated_at <= $3::timestamptz)`
+
+func (f DLQFilter) pgArgs() []any {
+	var older any
+	if !f.OlderThan.IsZero() { older = f.OlderThan.UTC() }
+	return []any{f.Queue, likeEscape(f.ErrorContains), older}
+}
+
+func (s *PostgresStorage) GetDLQJobs(ctx context.Context, f DLQFilter) ([]models.Job, error) {
+	limit := f.Limit
+	if limit <= 0 { limit = 50 }
+	return queryJobs(ctx, s.db, `
+SELECT `+jobCols+`
+FROM jobs`+pgDLQWhere+`
+ORDER BY updated_at DESC
+LIMIT $4`, append(f.pgArgs(), limit)...)
+}
+
+const pgResetDead = `
+UPDATE jobs
+SET state='pending', attempts=0, error='', next_retry=NULL, retry_delay_ms=0, worker_id=NULL, lease_until=NULL, updated_at=now()`
+
+func (s *PostgresStorage) RetryDLQJob(ctx context.Context, jobID string) error {
+	res, err := s.db.ExecContext(ctx, pgResetDead+` WHERE id=$1 AND state='dead'`, jobID)
+	if err != nil { return err }
+	if n, _ := res.RowsAffected(); n != 1 {
+		return fmt.Errorf("%w: %s", ErrNotInDLQ, jobID)
+	}
+	return nil
+}
+
+func (s *PostgresStorage) RetryDLQJobs(ctx context.Context, f DLQFilter) (int, error) {
+	res, err := s.db.ExecContext(ctx, pgResetDead+pgDLQWhere, f.pgArgs()...)
+	if err != nil { return 0, err }
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (s *PostgresStorage) PurgeDLQ(ctx context.Context, f DLQFilter) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil { return 0, err }
+	defer func() { _ = tx.Rollback() }()
+
+	for _, table := range []string{"job_logs", "job_attempts"} {
+		if _, err := tx.ExecContext(ctx, `
+DELETE FROM `+table+` WHERE job_id IN (SELECT id FROM jobs`+pgDLQWhere+`)`, f.pgArgs()...); err != nil {
+			return 0, err
+		}
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM jobs`+pgDLQWhere, f.pgArgs()...)
+	if err != nil { return 0, err }
+	n, err := res.RowsAffected()
+	if err != nil { return 0, err }
+	return int(n), tx.Commit()
+}
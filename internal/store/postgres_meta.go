@@ -0,0 +1,273 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/shashidhxr/queueCTL/pkg/models"
+)
+
+func (s *PostgresStorage) StartAttempt(ctx context.Context, jobID, leaseID, worker string) (id int64, number int, err error) {
+	err = s.db.QueryRowContext(ctx, `
+INSERT INTO job_attempts(job_id, number, worker, lease_id, started_at)
+VALUES($1, (SELECT COUNT(*) + 1 FROM job_attempts WHERE job_id = $1), $2, $3, $4)
+RETURNING id, number`,
+		jobID, worker, leaseID, time.Now().UTC()).Scan(&id, &number)
+	return id, number, err
+}
+
+func (s *PostgresStorage) FinishAttempt(ctx context.Context, id int64, u models.Usage, errStr string) error {
+	var code any
+	if u.ExitCode != nil { code = *u.ExitCode }
+	_, err := s.db.ExecContext(ctx, `
+UPDATE job_attempts SET ended_at=$1, exit_code=$2, error=$3, signal=$4, wall_ms=$5, user_ms=$6, sys_ms=$7, max_rss_kb=$8
+WHERE id=$9`, time.Now().UTC(), code, errStr, u.Signal, u.WallMS, u.UserMS, u.SysMS, u.MaxRSSKB, id)
+	return err
+}
+
+func (s *PostgresStorage) GetAttempts(ctx context.Context, jobID string) ([]models.Attempt, error) {
+	return queryAttempts(ctx, s.db, `
+SELECT `+attemptCols+` FROM job_attempts WHERE job_id = $1 ORDER BY number ASC`, jobID)
+}
+
+func (s *PostgresStorage) LastAttempts(ctx context.Context, jobIDs []string) (map[string]models.Attempt, error) {
+	out := map[string]models.Attempt{}
+	if len(jobIDs) == 0 { return out, nil }
+	attempts, err := queryAttempts(ctx, s.db, `
+SELECT DISTINCT ON (job_id) `+attemptCols+` FROM job_attempts
+WHERE job_id = ANY($1)
+ORDER BY job_id, number DESC`, pq.Array(jobIDs))
+	if err != nil { return nil, err }
+	for _, a := range attempts { out[a.JobID] = a }
+	return out, nil
+}
+
+func (s *PostgresStorage) AppendLog(ctx context.Context, jobID string, attempt int, stream, chunk string) error {
+	_, err := s.db.ExecContext(ctx, `
+INSERT INTO job_logs(job_id, ts, attempt, stream, chunk) VALUES($1, now(), $2, $3, $4)`, jobID, attempt, stream, chunk)
+	return err
+}
+
+func (s *PostgresStorage) GetLogs(ctx context.Context, jobID string, f LogFilter) ([]LogLine, error) {
+	if f.Limit <= 0 { f.Limit = 200 }
+	var since any
+	if !f.Since.IsZero() { since = f.Since.UTC() }
+	rows, err := s.db.QueryContext(ctx, `
+SELECT id, ts, attempt, stream, chunk FROM job_logs
+WHERE job_id = $1 AND id > $2
+  AND ($3 = '' OR stream = $3)
+  AND ($4::timestamptz IS NULL OR ts >= $4::timestamptz)
+  AND ($5 = 0 OR attempt = $5)
+ORDER BY id ASC LIMIT $6`, jobID, f.AfterID, f.Stream, since, f.Attempt, f.Limit)
+	if err != nil { return nil, err }
+	return scanLogs(rows)
+}
+
+func (s *PostgresStorage) TailLogs(ctx context.Context, jobID string, n int) ([]LogLine, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT id, ts, attempt, stream, chunk FROM (
+  SELECT id, ts, attempt, stream, chunk FROM job_logs WHERE job_id = $1 ORDER BY id DESC LIMIT $2
+) t ORDER BY id ASC`, jobID, n)
+	if err != nil { return nil, err }
+	return scanLogs(rows)
+}
+
+func (s *PostgresStorage) SaveSchedule(ctx context.Context, sc *models.Schedule) error {
+	now := time.Now().UTC()
+	if sc.CatchUp == "" { sc.CatchUp = models.CatchUpSkip }
+	if sc.Queue == "" { sc.Queue = models.DefaultQueue }
+	sc.CreatedAt, sc.UpdatedAt = now, now
+	sc.NextRunAt = sc.NextRunAt.UTC()
+	_, err := s.db.ExecContext(ctx, `
+INSERT INTO schedules (`+scheduleCols+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		sc.ID, sc.Spec, sc.Command, sc.Queue, sc.MaxRetries, sc.CatchUp, sc.Paused, sc.NextRunAt, nullableTime(sc.LastRunAt), sc.CreatedAt, sc.UpdatedAt)
+	return err
+}
+
+func (s *PostgresStorage) GetSchedule(ctx context.Context, id string) (*models.Schedule, error) {
+	sc, err := scanSchedule(s.db.QueryRowContext(ctx, `SELECT `+scheduleCols+` FROM schedules WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) { return nil, fmt.Errorf("%w: %s", ErrScheduleNotFound, id) }
+	return sc, err
+}
+
+func (s *PostgresStorage) ListSchedules(ctx context.Context) ([]models.Schedule, error) {
+	return querySchedules(ctx, s.db, `SELECT `+scheduleCols+` FROM schedules ORDER BY created_at ASC`)
+}
+
+func (s *PostgresStorage) DueSchedules(ctx context.Context, now time.Time) ([]models.Schedule, error) {
+	return querySchedules(ctx, s.db, `
+SELECT `+scheduleCols+` FROM schedules
+WHERE NOT paused AND next_run_at <= $1
+ORDER BY next_run_at ASC`, now.UTC())
+}
+
+func (s *PostgresStorage) PauseSchedule(ctx context.Context, id string) error {
+	return s.updateSchedule(ctx, id, `UPDATE schedules SET paused=TRUE, updated_at=now() WHERE id=$1`, id)
+}
+
+func (s *PostgresStorage) ResumeSchedule(ctx context.Context, id string, next time.Time) error {
+	return s.updateSchedule(ctx, id, `
+UPDATE schedules SET paused=FALSE, next_run_at=$1, updated_at=now() WHERE id=$2`, next.UTC(), id)
+}
+
+func (s *PostgresStorage) DeleteSchedule(ctx context.Context, id string) error {
+	return s.updateSchedule(ctx, id, `DELETE FROM schedules WHERE id=$1`, id)
+}
+
+func (s *PostgresStorage) updateSchedule(ctx context.Context, id, q string, args ...any) error {
+	res, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil { return err }
+	if n, _ := res.RowsAffected(); n != 1 {
+		return fmt.Errorf("%w: %s", ErrScheduleNotFound, id)
+	}
+	return nil
+}
+
+func (s *PostgresStorage) FireSchedule(ctx context.Context, sc *models.Schedule, next time.Time, jobs []*models.Job) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil { return false, err }
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now().UTC()
+	var last any
+	if len(jobs) > 0 { last = now }
+	res, err := tx.ExecContext(ctx, `
+UPDATE schedules
+SET next_run_at=$1, last_run_at=COALESCE($2::timestamptz, last_run_at), updated_at=$3
+WHERE id=$4 AND NOT paused AND next_run_at=$5`, next.UTC(), last, now, sc.ID, sc.NextRunAt)
+	if err != nil { return false, err }
+	if n, _ := res.RowsAffected(); n != 1 { return false, nil }
+
+	for _, j := range jobs {
+		if j.State == "" { j.State = models.StatePending }
+		if j.Queue == "" { j.Queue = models.DefaultQueue }
+		j.CreatedAt, j.UpdatedAt = now, now
+		if _, err := pgInsertJob(ctx, tx, "ON CONFLICT (id) DO NOTHING", j); err != nil { return false, err }
+	}
+	return true, tx.Commit()
+}
+
+func (s *PostgresStorage) GetQueueSettings(ctx context.Context, name string) (*models.QueueSettings, error) {
+	q, err := scanQueueSettings(s.db.QueryRowContext(ctx, `
+SELECT name, retry_policy, backoff, updated_at FROM queues WHERE name = $1`, name))
+	if errors.Is(err, sql.ErrNoRows) { return &models.QueueSettings{Name: name}, nil }
+	return q, err
+}
+
+func (s *PostgresStorage) ListQueueSettings(ctx context.Context) ([]models.QueueSettings, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT name, retry_policy, backoff, updated_at FROM queues ORDER BY name`)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	var out []models.QueueSettings
+	for rows.Next() {
+		q, err := scanQueueSettings(rows)
+		if err != nil { return nil, err }
+		out = append(out, *q)
+	}
+	return out, rows.Err()
+}
+
+func (s *PostgresStorage) SaveQueueSettings(ctx context.Context, q *models.QueueSettings) error {
+	policy, err := nullableJSON(q.RetryPolicy)
+	if err != nil { return err }
+	backoff, err := nullableJSON(q.Backoff)
+	if err != nil { return err }
+	q.UpdatedAt = time.Now().UTC()
+	_, err = s.db.ExecContext(ctx, `
+INSERT INTO queues(name, retry_policy, backoff, updated_at) VALUES($1, $2, $3, $4)
+ON CONFLICT(name) DO UPDATE SET retry_policy=excluded.retry_policy, backoff=excluded.backoff, updated_at=excluded.updated_at`,
+		q.Name, policy, backoff, q.UpdatedAt)
+	return err
+}
+
+func (s *PostgresStorage) DeleteQueueSettings(ctx context.Context, name string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM queues WHERE name = $1`, name)
+	return err
+}
+
+func (s *PostgresStorage) GetConfigValues(ctx context.Context) (map[string]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT key, value FROM config`)
+	if err != nil { return nil, err }
+	defer rows.Close()
+	out := map[string]string{}
+	for rows.Next() {
+		var k, v string
+		if err := rows.Scan(&k, &v); err != nil { return nil, err }
+		out[k] = v
+	}
+	return out, rows.Err()
+}
+
+func (s *PostgresStorage) SetConfig(ctx context.Context, key, value string) error {
+	_, err := s.db.ExecContext(ctx, `
+INSERT INTO config(key, value) VALUES($1, $2) ON CONFLICT(key) DO UPDATE SET value=excluded.value`, key, value)
+	return err
+}
+
+const pgStateExpr = `CASE WHEN state = 'pending' AND run_at > now() THEN 'scheduled' ELSE state END`
+
+func (s *PostgresStorage) GetJobStats(ctx context.Context) (map[models.JobState]int, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+pgStateExpr+` AS st, COUNT(*) FROM jobs GROUP BY st`)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	out := map[models.JobState]int{
+		models.StatePending: 0, models.StateProcessing: 0,
+		models.StateCompleted: 0, models.StateFailed: 0, models.StateDead: 0,
+		models.StateScheduled: 0, models.StateCancelled: 0,
+	}
+	for rows.Next() {
+		var st string; var n int
+		if err := rows.Scan(&st, &n); err != nil { return nil, err }
+		out[models.JobState(st)] = n
+	}
+	return out, rows.Err()
+}
+
+func (s *PostgresStorage) GetQueueStats(ctx context.Context) (map[string]map[models.JobState]int, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT queue, `+pgStateExpr+` AS st, COUNT(*) FROM jobs GROUP BY queue, st`)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	out := map[string]map[models.JobState]int{}
+	for rows.Next() {
+		var q, st string; var n int
+		if err := rows.Scan(&q, &st, &n); err != nil { return nil, err }
+		if out[q] == nil { out[q] = map[models.JobState]int{} }
+		out[q][models.JobState(st)] = n
+	}
+	return out, rows.Err()
+}
+
+func (s *PostgresStorage) GetAttemptStats(ctx context.Context, since time.Time) (map[string]AttemptStats, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT j.queue, COUNT(*),
+  COUNT(*) FILTER (WHERE a.exit_code = 0),
+  COUNT(*) FILTER (WHERE a.exit_code > 0),
+  COUNT(*) FILTER (WHERE a.error LIKE 'timeout after%'),
+  COUNT(*) FILTER (WHERE a.signal <> '' AND a.error NOT LIKE 'timeout after%' AND a.error NOT IN ('cancelled', 'job lease lost')),
+  CAST(AVG(a.wall_ms) AS BIGINT), MAX(a.wall_ms), SUM(a.user_ms), SUM(a.sys_ms), MAX(a.max_rss_kb)
+FROM job_attempts a JOIN jobs j ON j.id = a.job_id
+WHERE a.ended_at >= $1
+GROUP BY j.queue`, since.UTC())
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	out := map[string]AttemptStats{}
+	for rows.Next() {
+		var q string; var a AttemptStats
+		if err := rows.Scan(&q, &a.Attempts, &a.Succeeded, &a.Failed, &a.TimedOut, &a.Killed,
+			&a.AvgWallMS, &a.MaxWallMS, &a.UserMS, &a.SysMS, &a.MaxRSSKB); err != nil {
+			return nil, err
+		}
+		out[q] = a
+	}
+	return out, rows.Err()
+}
+
+var _ Store = (*PostgresStorage)(nil)
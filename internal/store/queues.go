@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/shashidhxr/queueCTL/pkg/models"
+)
+
+// GetQueueSettings returns the settings stored for a queue; a queue that was
+// never configured gets empty settings.
+func (s *SQLiteStorage) GetQueueSettings(ctx context.Context, name string) (*models.QueueSettings, error) {
+	q, err := scanQueueSettings(s.db.QueryRowContext(ctx, `
+SELECT name, retry_policy, backoff, updated_at FROM queues WHERE name = ?`, name))
+	if errors.Is(err, sql.ErrNoRows) { return &models.QueueSettings{Name: name}, nil }
+	return q, err
+}
+
+func (s *SQLiteStorage) ListQueueSettings(ctx context.Context) ([]models.QueueSettings, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT name, retry_policy, backoff, updated_at FROM queues ORDER BY name`)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	var out []models.QueueSettings
+	for rows.Next() {
+		q, err := scanQueueSettings(rows)
+		if err != nil { return nil, err }
+		out = append(out, *q)
+	}
+	return out, rows.Err()
+}
+
+func (s *SQLiteStorage) SaveQueueSettings(ctx context.Context, q *models.QueueSettings) error {
+	policy, err := nullableJSON(q.RetryPolicy)
+	if err != nil { return err }
+	backoff, err := nullableJSON(q.Backoff)
+	if err != nil { return err }
+	q.UpdatedAt = time.Now().UTC()
+	_, err = s.db.ExecContext(ctx, `
+INSERT INTO queues(name, retry_policy, backoff, updated_at) VALUES(?, ?, ?, ?)
+ON CONFLICT(name) DO UPDATE SET retry_policy=excluded.retry_policy, backoff=excluded.backoff, updated_at=excluded.updated_at`,
+		q.Name, policy, backoff, q.UpdatedAt)
+	return err
+}
+
+// DeleteQueueSettings drops a queue's settings; its jobs are untouched.
+func (s *SQLiteStorage) DeleteQueueSettings(ctx context.Context, name string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM queues WHERE name = ?`, name)
+	return err
+}
+
+func scanQueueSettings(r rowScanner) (*models.QueueSettings, error) {
+	var q models.QueueSettings
+	var policy, backoff sql.NullString
+	if err := r.Scan(&q.Name, &policy, &backoff, &q.UpdatedAt); err != nil { return nil, err }
+	var err error
+	if q.RetryPolicy, err = scanJSON[models.RetryPolicy](policy); err != nil { return nil, err }
+	if q.Backoff, err = scanJSON[models.BackoffPolicy](backoff); err != nil { return nil, err }
+	return &q, nil
+}
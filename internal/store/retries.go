@@ -2,40 +2,90 @@ package store
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/shashidhxr/queueCTL/pkg/models"
 )
 
-func (s *SQLiteStorage) SetCompleted(ctx context.Context, id string) error {
-	_, err := s.db.ExecContext(ctx, `UPDATE jobs SET state='completed', updated_at=CURRENT_TIMESTAMP WHERE id=?`, id)
-	return err
+// ErrLeaseLost is returned when a job is no longer leased by the caller,
+// typically because its lease expired and the reaper handed it to another
+// worker. The caller must not touch the job any further.
+var ErrLeaseLost = errors.New("job lease lost")
+
+// leaseHeld appends the fencing condition shared by every update a lease
+// holder makes, so a stale worker can never overwrite the new owner's result.
+const leaseHeld = ` AND state='processing' AND worker_id=?`
+
+func fenced(res sql.Result, err error) error {
+	if err != nil { return err }
+	if n, _ := res.RowsAffected(); n != 1 { return ErrLeaseLost }
+	return nil
 }
 
-func (s *SQLiteStorage) FailOrScheduleFixed(ctx context.Context, id string, attempts, maxRetries int, fixedDelay time.Duration, errStr string) error {
-	attempts++
-	if attempts > maxRetries {
-		_, err := s.db.ExecContext(ctx, `
-UPDATE jobs SET state='dead', attempts=?, error=?, updated_at=CURRENT_TIMESTAMP WHERE id=?`, attempts, errStr, id)
-		return err
-	}
-	next := time.Now().UTC().Add(fixedDelay)
-	_, err := s.db.ExecContext(ctx, `
-UPDATE jobs SET state='pending', attempts=?, next_retry=?, error=?, updated_at=CURRENT_TIMESTAMP WHERE id=?`,
-		attempts, next, errStr, id)
-	return err
+func leaseOwner(j *models.Job) string {
+	if j.WorkerID == nil { return "" }
+	return *j.WorkerID
+}
+
+// RenewLease extends the lease on a running job to until and reports whether
+// a cancel has been requested for it.
+func (s *SQLiteStorage) RenewLease(ctx context.Context, id, workerID string, until time.Time) (bool, error) {
+	if err := fenced(s.db.ExecContext(ctx, `
+UPDATE jobs SET lease_until=? WHERE id=?`+leaseHeld, until.UTC(), id, workerID)); err != nil { return false, err }
+	var cancel bool
+	err := s.db.QueryRowContext(ctx, `SELECT cancel_requested FROM jobs WHERE id=?`, id).Scan(&cancel)
+	return cancel, err
+}
+
+// SetCompleted records a successful run and the result the job reported, if any.
+func (s *SQLiteStorage) SetCompleted(ctx context.Context, id, workerID, result string) error {
+	return fenced(s.db.ExecContext(ctx, `
+UPDATE jobs SET state='completed', result=?, lease_until=NULL, updated_at=CURRENT_TIMESTAMP WHERE id=?`+leaseHeld, nullableString(result), id, workerID))
+}
+
+// SetCancelled records that the lease holder stopped the job on request.
+func (s *SQLiteStorage) SetCancelled(ctx context.Context, id, workerID string) error {
+	return fenced(s.db.ExecContext(ctx, `
+UPDATE jobs SET state='cancelled', error='cancelled', lease_until=NULL, updated_at=CURRENT_TIMESTAMP WHERE id=?`+leaseHeld, id, workerID))
+}
+
+// SetDead moves a job whose failure is not worth retrying straight to the DLQ.
+func (s *SQLiteStorage) SetDead(ctx context.Context, j *models.Job, errStr string) error {
+	j.Attempts++
+	return fenced(s.db.ExecContext(ctx, `
+UPDATE jobs SET state='dead', attempts=?, error=?, lease_until=NULL, updated_at=CURRENT_TIMESTAMP WHERE id=?`+leaseHeld,
+		j.Attempts, errStr, j.ID, leaseOwner(j)))
+}
+
+// Backoff is satisfied by core.BackoffStrategy; it is declared here so the
+// store doesn't depend on core.
+type Backoff interface {
+	Next(attempt int, prev time.Duration) time.Duration
+}
+
+// retryDelay is how long backoff waits after j's latest failed attempt,
+// given the delay it waited before.
+func retryDelay(j *models.Job, backoff Backoff) time.Duration {
+	return backoff.Next(j.Attempts, time.Duration(j.RetryDelayMS)*time.Millisecond)
 }
 
-func (s *SQLiteStorage) FailOrScheduleBackoff(ctx context.Context, j *models.Job, delay time.Duration, errStr string) error {
+// FailOrSchedule records a failed attempt by the lease holder: the job is
+// rescheduled after the delay backoff picks, or moved to the DLQ once retries
+// are exhausted. A job whose cancel was requested meanwhile is cancelled
+// instead of retried. j.RetryDelayMS is set to the delay chosen.
+func (s *SQLiteStorage) FailOrSchedule(ctx context.Context, j *models.Job, backoff Backoff, errStr string) error {
 	j.Attempts++
 	if j.Attempts > j.MaxRetries {
-		_, err := s.db.ExecContext(ctx, `
-UPDATE jobs SET state='dead', attempts=?, error=?, updated_at=CURRENT_TIMESTAMP WHERE id=?`, j.Attempts, errStr, j.ID)
-		return err
+		return fenced(s.db.ExecContext(ctx, `
+UPDATE jobs SET state='dead', attempts=?, error=?, lease_until=NULL, updated_at=CURRENT_TIMESTAMP WHERE id=?`+leaseHeld,
+			j.Attempts, errStr, j.ID, leaseOwner(j)))
 	}
+	delay := retryDelay(j, backoff)
 	next := time.Now().UTC().Add(delay)
-	_, err := s.db.ExecContext(ctx, `
-UPDATE jobs SET state='pending', attempts=?, next_retry=?, error=?, updated_at=CURRENT_TIMESTAMP WHERE id=?`,
-		j.Attempts, next, errStr, j.ID)
-	return err
+	j.RetryDelayMS = delay.Milliseconds()
+	return fenced(s.db.ExecContext(ctx, `
+UPDATE jobs SET state=CASE WHEN cancel_requested=1 THEN 'cancelled' ELSE 'pending' END, attempts=?, next_retry=?, retry_delay_ms=?, error=?, worker_id=NULL, lease_until=NULL, updated_at=CURRENT_TIMESTAMP WHERE id=?`+leaseHeld,
+		j.Attempts, next, j.RetryDelayMS, errStr, j.ID, leaseOwner(j)))
 }
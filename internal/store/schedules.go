@@ -0,0 +1,124 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shashidhxr/queueCTL/pkg/models"
+)
+
+var ErrScheduleNotFound = errors.New("schedule not found")
+
+const scheduleCols = `id, spec, command, queue, max_retries, catch_up, paused, next_run_at, last_run_at, created_at, updated_at`
+
+func scanSchedule(r rowScanner) (*models.Schedule, error) {
+	var sc models.Schedule
+	var last sql.NullTime
+	if err := r.Scan(&sc.ID, &sc.Spec, &sc.Command, &sc.Queue, &sc.MaxRetries, &sc.CatchUp, &sc.Paused, &sc.NextRunAt, &last, &sc.CreatedAt, &sc.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if last.Valid { t := last.Time; sc.LastRunAt = &t }
+	return &sc, nil
+}
+
+func (s *SQLiteStorage) SaveSchedule(ctx context.Context, sc *models.Schedule) error {
+	now := time.Now().UTC()
+	if sc.CatchUp == "" { sc.CatchUp = models.CatchUpSkip }
+	if sc.Queue == "" { sc.Queue = models.DefaultQueue }
+	sc.CreatedAt, sc.UpdatedAt = now, now
+	sc.NextRunAt = sc.NextRunAt.UTC()
+	_, err := s.db.ExecContext(ctx, `
+INSERT INTO schedules (`+scheduleCols+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sc.ID, sc.Spec, sc.Command, sc.Queue, sc.MaxRetries, sc.CatchUp, sc.Paused, sc.NextRunAt, nullableTime(sc.LastRunAt), sc.CreatedAt, sc.UpdatedAt)
+	return err
+}
+
+func (s *SQLiteStorage) GetSchedule(ctx context.Context, id string) (*models.Schedule, error) {
+	sc, err := scanSchedule(s.db.QueryRowContext(ctx, `SELECT `+scheduleCols+` FROM schedules WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) { return nil, fmt.Errorf("%w: %s", ErrScheduleNotFound, id) }
+	return sc, err
+}
+
+func (s *SQLiteStorage) ListSchedules(ctx context.Context) ([]models.Schedule, error) {
+	return querySchedules(ctx, s.db, `SELECT `+scheduleCols+` FROM schedules ORDER BY created_at ASC`)
+}
+
+// DueSchedules returns active schedules whose next tick is at or before now.
+func (s *SQLiteStorage) DueSchedules(ctx context.Context, now time.Time) ([]models.Schedule, error) {
+	return querySchedules(ctx, s.db, `
+SELECT `+scheduleCols+` FROM schedules
+WHERE paused = 0 AND next_run_at <= ?
+ORDER BY next_run_at ASC`, now.UTC())
+}
+
+func querySchedules(ctx context.Context, db querier, q string, args ...any) ([]models.Schedule, error) {
+	rows, err := db.QueryContext(ctx, q, args...)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	var out []models.Schedule
+	for rows.Next() {
+		sc, err := scanSchedule(rows)
+		if err != nil { return nil, err }
+		out = append(out, *sc)
+	}
+	return out, rows.Err()
+}
+
+func (s *SQLiteStorage) PauseSchedule(ctx context.Context, id string) error {
+	return s.updateSchedule(ctx, id, `UPDATE schedules SET paused=1, updated_at=CURRENT_TIMESTAMP WHERE id=?`, id)
+}
+
+// ResumeSchedule reactivates a schedule starting from next, so ticks that fell
+// inside the paused window are not caught up.
+func (s *SQLiteStorage) ResumeSchedule(ctx context.Context, id string, next time.Time) error {
+	return s.updateSchedule(ctx, id, `
+UPDATE schedules SET paused=0, next_run_at=?, updated_at=CURRENT_TIMESTAMP WHERE id=?`, next.UTC(), id)
+}
+
+func (s *SQLiteStorage) DeleteSchedule(ctx context.Context, id string) error {
+	return s.updateSchedule(ctx, id, `DELETE FROM schedules WHERE id=?`, id)
+}
+
+func (s *SQLiteStorage) updateSchedule(ctx context.Context, id, q string, args ...any) error {
+	res, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil { return err }
+	if n, _ := res.RowsAffected(); n != 1 {
+		return fmt.Errorf("%w: %s", ErrScheduleNotFound, id)
+	}
+	return nil
+}
+
+// FireSchedule advances sc from the next_run_at it was read with to next and
+// inserts jobs in the same transaction. The advance is a compare-and-swap on
+// next_run_at, so when several schedulers race on the same tick exactly one
+// wins; the others get false and insert nothing.
+func (s *SQLiteStorage) FireSchedule(ctx context.Context, sc *models.Schedule, next time.Time, jobs []*models.Job) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil { return false, err }
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now().UTC()
+	var last any
+	if len(jobs) > 0 { last = now }
+	res, err := tx.ExecContext(ctx, `
+UPDATE schedules
+SET next_run_at=?, last_run_at=COALESCE(?, last_run_at), updated_at=?
+WHERE id=? AND paused=0 AND next_run_at=?`, next.UTC(), last, now, sc.ID, sc.NextRunAt)
+	if err != nil { return false, err }
+	if n, _ := res.RowsAffected(); n != 1 { return false, nil }
+
+	for _, j := range jobs {
+		if j.State == "" { j.State = models.StatePending }
+		if j.Queue == "" { j.Queue = models.DefaultQueue }
+		j.CreatedAt, j.UpdatedAt = now, now
+		// Job IDs are derived from the tick, so a replayed tick is a no-op.
+		if _, err := insertJob(ctx, tx, "INSERT OR IGNORE", j); err != nil { return false, err }
+	}
+	if err := tx.Commit(); err != nil { return false, err }
+	if len(jobs) > 0 { s.jobsChanged() }
+	return true, nil
+}
@@ -1,32 +1,137 @@
 package store
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
 type SQLiteStorage struct {
-	db *sql.DB
+	db *sqliteDB
+	*migrator
+
+	wake    *notifier
+	sockets *socketWaker
 }
 
+// sqliteParams are added to every DSN. WAL lets readers run alongside the
+// single writer; busy_timeout makes a writer wait for the lock instead of
+// failing; _txlock=immediate takes the write lock at BEGIN, so a transaction
+// never fails halfway when it upgrades from reading to writing (which
+// busy_timeout can't help with).
+const sqliteParams = "_journal_mode=WAL&_busy_timeout=5000&_txlock=immediate&_synchronous=NORMAL"
+
+// sqliteMaxConns caps the pool. Only one connection can write at a time, so
+// more connections just queue on the file lock instead of in database/sql.
+const sqliteMaxConns = 8
+
+// NewSQLiteStorage opens (creating if needed) the database at dbPath and
+// migrates its schema to the latest version.
 func NewSQLiteStorage(dbPath string) (*SQLiteStorage, error) {
+	return newSQLiteStorage(dbPath, true)
+}
+
+func newSQLiteStorage(dbPath string, migrate bool) (*SQLiteStorage, error) {
 	dir := filepath.Dir(dbPath)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 	 return nil, err
 	}
-	db, err := sql.Open("sqlite3", dbPath)
+	sep := "?"
+	if strings.Contains(dbPath, "?") { sep = "&" }
+	db, err := sql.Open("sqlite3", dbPath+sep+sqliteParams)
 	if err != nil {
 	 return nil, err
 	}
-	s := &SQLiteStorage{db: db}
-	if err := s.runMigrations(); err != nil {
-		_ = db.Close()
-		return nil, err
+	db.SetMaxOpenConns(sqliteMaxConns)
+	db.SetMaxIdleConns(sqliteMaxConns)
+	s := &SQLiteStorage{
+		db: &sqliteDB{db}, migrator: newSQLiteMigrator(db),
+		wake: newNotifier(), sockets: newSocketWaker(dbPath),
+	}
+	if migrate {
+		if err := retryBusy(context.Background(), s.migrateUp); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
 	}
 	return s, nil
 }
 
-func (s *SQLiteStorage) Close() error { return s.db.Close() }
+func (s *SQLiteStorage) Close() error {
+	s.sockets.close()
+	return s.db.Close()
+}
+
+// JobsAvailable implements Waker. The first call also makes this process
+// reachable by writers in other processes.
+func (s *SQLiteStorage) JobsAvailable() <-chan struct{} {
+	s.sockets.listen(s.wake.notify)
+	return s.wake.JobsAvailable()
+}
+
+// jobsChanged wakes idle workers, in this process and others, after a write
+// that may have made a job runnable.
+func (s *SQLiteStorage) jobsChanged() {
+	s.wake.notify()
+	s.sockets.broadcast()
+}
+
+// sqliteDB retries statements and transactions that still hit SQLITE_BUSY
+// after busy_timeout, e.g. when many processes start at once. Rows and Row
+// errors surface later and are not retried, so writes go through ExecContext
+// rather than QueryRowContext with RETURNING.
+type sqliteDB struct {
+	*sql.DB
+}
+
+func (d *sqliteDB) ExecContext(ctx context.Context, q string, args ...any) (res sql.Result, err error) {
+	err = retryBusy(ctx, func() error {
+		res, err = d.DB.ExecContext(ctx, q, args...)
+		return err
+	})
+	return res, err
+}
+
+func (d *sqliteDB) QueryContext(ctx context.Context, q string, args ...any) (rows *sql.Rows, err error) {
+	err = retryBusy(ctx, func() error {
+		rows, err = d.DB.QueryContext(ctx, q, args...)
+		return err
+	})
+	return rows, err
+}
+
+func (d *sqliteDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (tx *sql.Tx, err error) {
+	err = retryBusy(ctx, func() error {
+		tx, err = d.DB.BeginTx(ctx, opts)
+		return err
+	})
+	return tx, err
+}
+
+func isBusy(err error) bool {
+	var se sqlite3.Error
+	return errors.As(err, &se) && (se.Code == sqlite3.ErrBusy || se.Code == sqlite3.ErrLocked)
+}
+
+// retryBusy runs fn until it succeeds, fails with something other than
+// SQLITE_BUSY, or ctx is done, backing off with jitter between tries.
+func retryBusy(ctx context.Context, fn func() error) error {
+	delay := 5 * time.Millisecond
+	for i := 0; ; i++ {
+		err := fn()
+		if !isBusy(err) || i == 10 { return err }
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay/2 + rand.N(delay)):
+		}
+		delay = min(2*delay, time.Second)
+	}
+}
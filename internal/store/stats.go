@@ -2,18 +2,22 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/shashidhxr/queueCTL/pkg/models"
 )
 
 func (s *SQLiteStorage) GetJobStats(ctx context.Context) (map[models.JobState]int, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT state, COUNT(*) FROM jobs GROUP BY state`)
+	rows, err := s.db.QueryContext(ctx, `
+SELECT CASE WHEN state = 'pending' AND run_at > CURRENT_TIMESTAMP THEN 'scheduled' ELSE state END AS st, COUNT(*)
+FROM jobs GROUP BY st`)
 	if err != nil { return nil, err }
 	defer rows.Close()
 
 	out := map[models.JobState]int{
 		models.StatePending: 0, models.StateProcessing: 0,
 		models.StateCompleted: 0, models.StateFailed: 0, models.StateDead: 0,
+		models.StateScheduled: 0, models.StateCancelled: 0,
 	}
 	for rows.Next() {
 		var st string; var n int
@@ -22,3 +26,63 @@ func (s *SQLiteStorage) GetJobStats(ctx context.Context) (map[models.JobState]in
 	}
 	return out, rows.Err()
 }
+
+// GetQueueStats is GetJobStats broken down by queue.
+func (s *SQLiteStorage) GetQueueStats(ctx context.Context) (map[string]map[models.JobState]int, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT queue, CASE WHEN state = 'pending' AND run_at > CURRENT_TIMESTAMP THEN 'scheduled' ELSE state END AS st, COUNT(*)
+FROM jobs GROUP BY queue, st`)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	out := map[string]map[models.JobState]int{}
+	for rows.Next() {
+		var q, st string; var n int
+		if err := rows.Scan(&q, &st, &n); err != nil { return nil, err }
+		if out[q] == nil { out[q] = map[models.JobState]int{} }
+		out[q][models.JobState(st)] = n
+	}
+	return out, rows.Err()
+}
+
+// AttemptStats summarizes the finished attempts of one queue, split by how
+// they ended so OOM kills stand out from timeouts and ordinary failures.
+type AttemptStats struct {
+	Attempts  int   `json:"attempts"`
+	Succeeded int   `json:"succeeded"`
+	Failed    int   `json:"failed"`    // exited non-zero
+	TimedOut  int   `json:"timed_out"`
+	Killed    int   `json:"killed"`    // killed by a signal we didn't send (e.g. the OOM killer)
+	AvgWallMS int64 `json:"avg_wall_ms"`
+	MaxWallMS int64 `json:"max_wall_ms"`
+	UserMS    int64 `json:"user_cpu_ms"`
+	SysMS     int64 `json:"sys_cpu_ms"`
+	MaxRSSKB  int64 `json:"max_rss_kb"`
+}
+
+// GetAttemptStats aggregates attempts that ended at or after since, by queue.
+func (s *SQLiteStorage) GetAttemptStats(ctx context.Context, since time.Time) (map[string]AttemptStats, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT j.queue, COUNT(*),
+  COALESCE(SUM(a.exit_code = 0), 0),
+  COALESCE(SUM(a.exit_code > 0), 0),
+  COALESCE(SUM(a.error LIKE 'timeout after%'), 0),
+  COALESCE(SUM(a.signal != '' AND a.error NOT LIKE 'timeout after%' AND a.error NOT IN ('cancelled', 'job lease lost')), 0),
+  CAST(AVG(a.wall_ms) AS INTEGER), MAX(a.wall_ms), SUM(a.user_ms), SUM(a.sys_ms), MAX(a.max_rss_kb)
+FROM job_attempts a JOIN jobs j ON j.id = a.job_id
+WHERE a.ended_at >= ?
+GROUP BY j.queue`, since.UTC())
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	out := map[string]AttemptStats{}
+	for rows.Next() {
+		var q string; var a AttemptStats
+		if err := rows.Scan(&q, &a.Attempts, &a.Succeeded, &a.Failed, &a.TimedOut, &a.Killed,
+			&a.AvgWallMS, &a.MaxWallMS, &a.UserMS, &a.SysMS, &a.MaxRSSKB); err != nil {
+			return nil, err
+		}
+		out[q] = a
+	}
+	return out, rows.Err()
+}
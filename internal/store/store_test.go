@@ -0,0 +1,368 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shashidhxr/queueCTL/pkg/models"
+)
+
+// testDSN is a Postgres database the tests may wipe, e.g.
+// QUEUECTL_TEST_DB="postgres://localhost/queuectl_test?sslmode=disable".
+// Without it the Postgres cases are skipped.
+func testDSN() string {
+	dsn := os.Getenv("QUEUECTL_TEST_DB")
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		return dsn
+	}
+	return ""
+}
+
+// openTestStore opens an empty store of the given backend, closed when the
+// test ends.
+func openTestStore(t testing.TB, backend string) Store {
+	t.Helper()
+	var s Store
+	switch backend {
+	case "memory":
+		s = NewMemoryStorage()
+	case "sqlite":
+		sq, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "queue.db"))
+		if err != nil { t.Fatal(err) }
+		s = sq
+	case "postgres":
+		dsn := testDSN()
+		if dsn == "" { t.Skip("QUEUECTL_TEST_DB is not set to a postgres:// DSN") }
+		pg, err := NewPostgresStorage(dsn)
+		if err != nil { t.Fatal(err) }
+		if _, err := pg.db.Exec(`TRUNCATE jobs, job_attempts, job_logs, schedules, queues, config`); err != nil {
+			_ = pg.Close()
+			t.Fatal(err)
+		}
+		s = pg
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+type fixedBackoff time.Duration
+
+func (d fixedBackoff) Next(int, time.Duration) time.Duration { return time.Duration(d) }
+
+var backends = []string{"memory", "sqlite", "postgres"}
+
+// forEachStore runs fn as a subtest against an empty store of every backend.
+func forEachStore(t *testing.T, fn func(t *testing.T, s Store)) {
+	for _, b := range backends {
+		t.Run(b, func(t *testing.T) { fn(t, openTestStore(t, b)) })
+	}
+}
+
+func saveJobs(t testing.TB, s Store, n int, mod func(i int, j *models.Job)) []string {
+	t.Helper()
+	ids := make([]string, n)
+	for i := range ids {
+		j := &models.Job{ID: fmt.Sprintf("job-%04d", i), Command: "true", MaxRetries: 3, TimeoutSeconds: 30}
+		if mod != nil { mod(i, j) }
+		if err := s.SaveJob(context.Background(), j); err != nil { t.Fatal(err) }
+		ids[i] = j.ID
+	}
+	return ids
+}
+
+// TestAcquireJobsLeasesEachJobOnce races several workers over one queue: on
+// Postgres this exercises FOR UPDATE SKIP LOCKED, on SQLite BEGIN IMMEDIATE.
+func TestAcquireJobsLeasesEachJobOnce(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		saveJobs(t, s, 300, nil)
+
+		var mu sync.Mutex
+		leased := map[string]int{}
+		tokens := map[string]bool{}
+		var wg sync.WaitGroup
+		errs := make(chan error, 8)
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					jobs, err := s.AcquireJobs(ctx, "", 7)
+					if err != nil { errs <- err; return }
+					if len(jobs) == 0 { return }
+					mu.Lock()
+					for _, j := range jobs {
+						leased[j.ID]++
+						if j.State != models.StateProcessing || j.WorkerID == nil || tokens[*j.WorkerID] {
+							errs <- fmt.Errorf("job %s leased as %s with token %v", j.ID, j.State, j.WorkerID)
+						} else {
+							tokens[*j.WorkerID] = true
+						}
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs { t.Error(err) }
+
+		if len(leased) != 300 { t.Errorf("leased %d distinct jobs, want 300", len(leased)) }
+		for id, n := range leased {
+			if n != 1 { t.Errorf("job %s leased %d times", id, n) }
+		}
+	})
+}
+
+func TestAcquireJobsOrder(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		future := time.Now().Add(time.Hour)
+		saveJobs(t, s, 4, func(i int, j *models.Job) {
+			switch i {
+			case 1:
+				j.Priority = 5
+			case 2:
+				j.RunAt = &future // not eligible yet
+			case 3:
+				j.Queue = "other"
+			}
+		})
+		jobs, err := s.AcquireJobs(ctx, models.DefaultQueue, 10)
+		if err != nil { t.Fatal(err) }
+		var got []string
+		for _, j := range jobs { got = append(got, j.ID) }
+		if want := "job-0001,job-0000"; strings.Join(got, ",") != want {
+			t.Errorf("AcquireJobs(default) = %v, want %s", got, want)
+		}
+	})
+}
+
+// TestRunAtDefersAcquisition checks that a job with a future run_at is
+// reported as scheduled and not leased, then leased once it is due.
+func TestRunAtDefersAcquisition(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		// SQLite compares run_at with CURRENT_TIMESTAMP, which has whole seconds.
+		runAt := time.Now().Add(time.Second)
+		saveJobs(t, s, 1, func(_ int, j *models.Job) { j.RunAt = &runAt })
+
+		if j, err := s.AcquireJob(ctx, ""); err != nil || j != nil {
+			t.Fatalf("AcquireJob() before run_at = %v, %v; want nothing", j, err)
+		}
+		stats, err := s.GetJobStats(ctx)
+		if err != nil { t.Fatal(err) }
+		if stats[models.StateScheduled] != 1 || stats[models.StatePending] != 0 {
+			t.Errorf("stats before run_at = %v, want 1 scheduled and 0 pending", stats)
+		}
+		if jobs, err := s.ListJobs(ctx, JobFilter{State: models.StateScheduled}); err != nil || len(jobs) != 1 {
+			t.Errorf("ListJobs(scheduled) = %d jobs, %v; want 1", len(jobs), err)
+		}
+
+		time.Sleep(time.Until(runAt.Truncate(time.Second).Add(time.Second)))
+		stats, err = s.GetJobStats(ctx)
+		if err != nil { t.Fatal(err) }
+		if stats[models.StateScheduled] != 0 || stats[models.StatePending] != 1 {
+			t.Errorf("stats once due = %v, want 0 scheduled and 1 pending", stats)
+		}
+		j, err := s.AcquireJob(ctx, "")
+		if err != nil || j == nil || j.ID != "job-0000" {
+			t.Fatalf("AcquireJob() once due = %v, %v; want job-0000", j, err)
+		}
+	})
+}
+
+func TestAcquireJobByID(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		future := time.Now().Add(time.Hour)
+		saveJobs(t, s, 3, func(i int, j *models.Job) {
+			j.Priority = i // job-0002 would come first from AcquireJobs
+			if i == 1 { j.RunAt = &future }
+		})
+		j, err := s.AcquireJobByID(ctx, "job-0000")
+		if err != nil || j == nil || j.ID != "job-0000" || j.State != models.StateProcessing {
+			t.Fatalf("AcquireJobByID(job-0000) = %+v, %v; want it leased", j, err)
+		}
+		for _, id := range []string{"job-0000", "job-0001", "missing"} {
+			if j, err := s.AcquireJobByID(ctx, id); err != nil || j != nil {
+				t.Errorf("AcquireJobByID(%s) = %+v, %v; want nothing (leased, not due or unknown)", id, j, err)
+			}
+		}
+	})
+}
+
+// growingBackoff waits a second longer than last time and remembers which
+// attempts it was asked about.
+type growingBackoff struct{ attempts []int }
+
+func (b *growingBackoff) Next(attempt int, prev time.Duration) time.Duration {
+	b.attempts = append(b.attempts, attempt)
+	return prev + time.Second
+}
+
+// TestFailOrScheduleAppliesBackoff checks that a retry is scheduled with the
+// delay the backoff picks from the attempt number and the previous delay, and
+// that a job out of retries goes to the DLQ without asking it.
+func TestFailOrScheduleAppliesBackoff(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		saveJobs(t, s, 2, func(i int, j *models.Job) { j.MaxRetries = 1 - i })
+		b := &growingBackoff{}
+
+		j, err := s.AcquireJobByID(ctx, "job-0000")
+		if err != nil || j == nil { t.Fatalf("AcquireJobByID() = %v, %v", j, err) }
+		j.RetryDelayMS = 2000 // as if an earlier retry had waited 2s
+		if err := s.FailOrSchedule(ctx, j, b, "boom"); err != nil { t.Fatal(err) }
+		got, err := s.GetJob(ctx, j.ID)
+		if err != nil { t.Fatal(err) }
+		if got.State != models.StatePending || got.Attempts != 1 || got.RetryDelayMS != 3000 || got.NextRetry == nil {
+			t.Fatalf("job = %s, attempts %d, delay %dms, next_retry %v; want pending after 3s", got.State, got.Attempts, got.RetryDelayMS, got.NextRetry)
+		}
+		if wait := time.Until(*got.NextRetry); wait > 3*time.Second || wait < time.Second {
+			t.Errorf("next retry in %s, want about 3s", wait)
+		}
+
+		j, err = s.AcquireJobByID(ctx, "job-0001")
+		if err != nil || j == nil { t.Fatalf("AcquireJobByID() = %v, %v", j, err) }
+		if err := s.FailOrSchedule(ctx, j, b, "boom"); err != nil { t.Fatal(err) }
+		if got, err := s.GetJob(ctx, j.ID); err != nil || got.State != models.StateDead {
+			t.Errorf("job out of retries = %v, %v; want dead", got, err)
+		}
+		if fmt.Sprint(b.attempts) != "[1]" { t.Errorf("backoff asked about attempts %v, want [1]", b.attempts) }
+	})
+}
+
+func TestFailOrScheduleHonorsCancelRequest(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		saveJobs(t, s, 1, nil)
+		j, err := s.AcquireJob(ctx, "")
+		if err != nil || j == nil { t.Fatalf("AcquireJob() = %v, %v", j, err) }
+		if _, err := s.CancelJob(ctx, j.ID); err != nil { t.Fatal(err) }
+		if err := s.FailOrSchedule(ctx, j, fixedBackoff(0), "boom"); err != nil { t.Fatal(err) }
+		if got, err := s.GetJob(ctx, j.ID); err != nil || got.State != models.StateCancelled {
+			t.Errorf("failed job with a cancel request = %v, %v; want cancelled", got, err)
+		}
+		if got, err := s.AcquireJob(ctx, ""); err != nil || got != nil {
+			t.Errorf("AcquireJob() = %v, %v; want the cancelled job not to run again", got, err)
+		}
+	})
+}
+
+// TestLeaseFencing checks that once a lease has expired and the job has been
+// requeued, the old holder can no longer record an outcome.
+func TestLeaseFencing(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		saveJobs(t, s, 1, nil)
+		stale, err := s.AcquireJob(ctx, "")
+		if err != nil || stale == nil { t.Fatalf("AcquireJob() = %v, %v", stale, err) }
+
+		// Let the lease run out, as if the worker had been paused.
+		if _, err := s.RenewLease(ctx, stale.ID, *stale.WorkerID, time.Now().Add(-time.Minute)); err != nil { t.Fatal(err) }
+		if err := s.RequeueExpiredLeases(ctx); err != nil { t.Fatal(err) }
+		current, err := s.AcquireJob(ctx, "")
+		if err != nil || current == nil { t.Fatalf("AcquireJob() after requeue = %v, %v", current, err) }
+		if *current.WorkerID == *stale.WorkerID { t.Fatal("re-leased job kept the old lease token") }
+
+		if _, err := s.RenewLease(ctx, stale.ID, *stale.WorkerID, time.Now().Add(time.Minute)); !errors.Is(err, ErrLeaseLost) {
+			t.Errorf("RenewLease with a stale token = %v, want ErrLeaseLost", err)
+		}
+		if err := s.SetCompleted(ctx, stale.ID, *stale.WorkerID, "stale"); !errors.Is(err, ErrLeaseLost) {
+			t.Errorf("SetCompleted with a stale token = %v, want ErrLeaseLost", err)
+		}
+		if err := s.FailOrSchedule(ctx, stale, fixedBackoff(time.Second), "boom"); !errors.Is(err, ErrLeaseLost) {
+			t.Errorf("FailOrSchedule with a stale token = %v, want ErrLeaseLost", err)
+		}
+		if err := s.SetDead(ctx, stale, "boom"); !errors.Is(err, ErrLeaseLost) {
+			t.Errorf("SetDead with a stale token = %v, want ErrLeaseLost", err)
+		}
+
+		if err := s.SetCompleted(ctx, current.ID, *current.WorkerID, "42"); err != nil { t.Fatalf("SetCompleted by the holder: %v", err) }
+		j, err := s.GetJob(ctx, current.ID)
+		if err != nil { t.Fatal(err) }
+		if j.State != models.StateCompleted || j.Result != "42" || j.Attempts != 0 {
+			t.Errorf("job = %s result %q attempts %d, want completed with result 42 and no failed attempts", j.State, j.Result, j.Attempts)
+		}
+		if err := s.SetCompleted(ctx, current.ID, *current.WorkerID, "again"); !errors.Is(err, ErrLeaseLost) {
+			t.Errorf("SetCompleted twice = %v, want ErrLeaseLost", err)
+		}
+	})
+}
+
+// TestJobsAvailable checks that saving a job wakes an idle worker. On
+// Postgres the job is saved through a second connection pool, so the wakeup
+// has to come through the LISTEN/NOTIFY trigger.
+func TestJobsAvailable(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b, func(t *testing.T) {
+			s := openTestStore(t, b)
+			writer := s
+			if b == "postgres" { writer = openTestStore(t, b) }
+			wake := s.(Waker).JobsAvailable()
+			for drained := false; !drained; {
+				select {
+				case <-wake:
+				default:
+					drained = true
+				}
+			}
+
+			saveJobs(t, writer, 1, nil)
+			select {
+			case <-wake:
+			case <-time.After(5 * time.Second):
+				t.Fatal("no wakeup within 5s of saving a job")
+			}
+		})
+	}
+}
+
+func TestDLQFilterAndPurge(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		ctx := context.Background()
+		errs := []string{"50% off", "exit status 1", "no_such_file"}
+		saveJobs(t, s, len(errs), nil)
+		for range errs {
+			j, err := s.AcquireJob(ctx, "")
+			if err != nil || j == nil { t.Fatalf("AcquireJob() = %v, %v", j, err) }
+			if _, _, err := s.StartAttempt(ctx, j.ID, *j.WorkerID, "test/1"); err != nil { t.Fatal(err) }
+			var n int
+			fmt.Sscanf(j.ID, "job-%d", &n)
+			if err := s.SetDead(ctx, j, errs[n]); err != nil { t.Fatal(err) }
+		}
+
+		tests := []struct {
+			contains string
+			want     int
+		}{
+			{"", 3},
+			{"%", 1},
+			{"_", 1},
+			{"EXIT", 1},
+			{"exit_status", 0},
+		}
+		for _, tt := range tests {
+			jobs, err := s.GetDLQJobs(ctx, DLQFilter{ErrorContains: tt.contains})
+			if err != nil { t.Fatal(err) }
+			if len(jobs) != tt.want { t.Errorf("GetDLQJobs(error contains %q) = %d jobs, want %d", tt.contains, len(jobs), tt.want) }
+		}
+
+		n, err := s.PurgeDLQ(ctx, DLQFilter{ErrorContains: "%"})
+		if err != nil || n != 1 { t.Fatalf("PurgeDLQ(%%) = %d, %v; want 1", n, err) }
+		if a, err := s.GetAttempts(ctx, "job-0000"); err != nil || len(a) != 0 {
+			t.Errorf("attempts of the purged job = %v, %v; want none", a, err)
+		}
+		if a, err := s.GetAttempts(ctx, "job-0001"); err != nil || len(a) != 1 {
+			t.Errorf("attempts of a kept job = %v, %v; want 1", a, err)
+		}
+	})
+}
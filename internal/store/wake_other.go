@@ -0,0 +1,13 @@
+//go:build !unix
+
+package store
+
+// socketWaker is a no-op where Unix datagram sockets aren't available;
+// workers in other processes find new jobs by polling.
+type socketWaker struct{}
+
+func newSocketWaker(dbPath string) *socketWaker { return &socketWaker{} }
+
+func (w *socketWaker) listen(wake func()) {}
+func (w *socketWaker) broadcast()         {}
+func (w *socketWaker) close()             {}
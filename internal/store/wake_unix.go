@@ -0,0 +1,86 @@
+//go:build unix
+
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// socketWaker carries wakeups between processes sharing a SQLite file: every
+// process running workers listens on a Unix datagram socket in a directory
+// derived from the database path, and every write that makes a job runnable
+// sends one byte to each socket there.
+type socketWaker struct {
+	dir  string
+	self string
+
+	once sync.Once
+	conn *net.UnixConn
+}
+
+func newSocketWaker(dbPath string) *socketWaker {
+	abs, err := filepath.Abs(dbPath)
+	if err != nil { abs = dbPath }
+	sum := sha256.Sum256([]byte(abs))
+	// Under the temp dir rather than next to the DB: socket paths are limited
+	// to about 100 bytes.
+	dir := filepath.Join(os.TempDir(), "queuectl-"+hex.EncodeToString(sum[:6]))
+	return &socketWaker{dir: dir, self: filepath.Join(dir, fmt.Sprintf("%d.sock", os.Getpid()))}
+}
+
+// listen starts calling wake for every datagram received. If the socket
+// can't be created, workers simply rely on polling.
+func (w *socketWaker) listen(wake func()) {
+	w.once.Do(func() {
+		if err := os.MkdirAll(w.dir, 0o700); err != nil { return }
+		_ = os.Remove(w.self) // left by an earlier process with our pid
+		conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: w.self, Net: "unixgram"})
+		if err != nil { return }
+		w.conn = conn
+		go func() {
+			buf := make([]byte, 1)
+			for {
+				if _, _, err := conn.ReadFrom(buf); err != nil { return }
+				wake()
+			}
+		}()
+	})
+}
+
+// broadcast wakes the listening processes other than this one, removing
+// sockets left behind by processes that died.
+func (w *socketWaker) broadcast() {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil { return }
+	for _, e := range entries {
+		path := filepath.Join(w.dir, e.Name())
+		if path == w.self || !strings.HasSuffix(path, ".sock") { continue }
+		conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+		if err != nil {
+			if errors.Is(err, syscall.ECONNREFUSED) { _ = os.Remove(path) }
+			continue
+		}
+		// A full receive buffer means that process already has wakeups queued.
+		_ = conn.SetWriteDeadline(time.Now().Add(50 * time.Millisecond))
+		_, _ = conn.Write([]byte{1})
+		_ = conn.Close()
+	}
+}
+
+func (w *socketWaker) close() {
+	w.once.Do(func() {}) // no listener may start after this
+	if w.conn != nil {
+		_ = w.conn.Close()
+		_ = os.Remove(w.self)
+	}
+}
@@ -10,11 +10,29 @@ const (
     StateCompleted  JobState = "completed"
     StateFailed     JobState = "failed"
     StateDead       JobState = "dead"
+    StateCancelled  JobState = "cancelled"
+
+    // StateScheduled is never stored: it is reported for pending jobs whose
+    // run_at is still in the future.
+    StateScheduled JobState = "scheduled"
 )
 
+// Terminal reports whether a job in state s will never run again.
+func (s JobState) Terminal() bool {
+    switch s {
+    case StateCompleted, StateFailed, StateDead, StateCancelled:
+        return true
+    }
+    return false
+}
+
+// DefaultQueue receives jobs enqueued without an explicit queue.
+const DefaultQueue = "default"
+
 type Job struct {
 	ID         string    `json:"id"`
 	Command    string    `json:"command"`
+	Queue      string    `json:"queue"`
 	State      JobState  `json:"state"`
 	Attempts   int       `json:"attempts"`
 	MaxRetries int       `json:"max_retries"`
@@ -22,13 +40,111 @@ type Job struct {
 	UpdatedAt  time.Time `json:"updated_at"`
 	Error      string    `json:"error,omitempty"`
 	NextRetry  *time.Time `json:"next_retry,omitempty"`
+	RunAt      *time.Time `json:"run_at,omitempty"` // not eligible before this time
+	Priority   int        `json:"priority"`         // higher runs first
 
-	TimeoutSeconds int        `json:"timeout_seconds"`       // per-job hard timeout
+	TimeoutSeconds int        `json:"timeout_seconds"`        // per-job hard timeout
+	BackoffBase    float64    `json:"backoff_base,omitempty"` // legacy; enqueue --backoff now sets Backoff.Base
     WorkerID       *string    `json:"worker_id,omitempty"`   // who leased it
     LeaseUntil     *time.Time `json:"lease_until,omitempty"` // visibility timeout
+    CancelRequested bool      `json:"cancel_requested,omitempty"` // the lease holder should stop the job
+
+    RetryPolicy *RetryPolicy   `json:"retry_policy,omitempty"` // overrides the queue's policy
+    Backoff     *BackoffPolicy `json:"backoff,omitempty"`      // overrides the queue's and config's backoff, field by field
+    RetryDelayMS int64         `json:"retry_delay_ms,omitempty"` // delay before the pending retry, for decorrelated jitter
+    Result       string        `json:"result,omitempty"`         // what a completed job reported, see QUEUECTL_RESULT_FILE
+
+    LastAttempt *Attempt `json:"last_attempt,omitempty"` // filled in by ListJobs
+}
+
+// RetryPolicy decides which failures are worth retrying. Failures it marks as
+// fatal skip the remaining retries and go straight to the DLQ. A job can also
+// ask for a retry after a given delay by printing "##queuectl retry-after 30s".
+type RetryPolicy struct {
+	RetryOn    []int  `json:"retry_on,omitempty"`    // if set, only these exit codes are retried
+	FatalOn    []int  `json:"fatal_on,omitempty"`    // exit codes that are never retried
+	FatalMatch string `json:"fatal_match,omitempty"` // regexp on stderr marking a failure fatal
+	RetryMatch string `json:"retry_match,omitempty"` // regexp on stderr marking a failure retryable regardless of exit code
+}
+
+// Backoff strategies.
+const (
+	BackoffFixed              = "fixed"
+	BackoffLinear             = "linear"
+	BackoffExponential        = "exponential"
+	BackoffDecorrelatedJitter = "decorrelated-jitter"
+	BackoffFullJitter         = "full-jitter"
+)
+
+// BackoffPolicy selects how long to wait between retries. Zero fields are
+// inherited from the queue's policy and then from config.
+type BackoffPolicy struct {
+	Strategy string  `json:"strategy,omitempty"`
+	Base     float64 `json:"base,omitempty"`   // growth factor of exponential and full-jitter
+	MinMS    int64   `json:"min_ms,omitempty"` // fixed delay, linear step, and unit of the others
+	MaxMS    int64   `json:"max_ms,omitempty"` // cap on every delay
+}
+
+// QueueSettings holds per-queue defaults for jobs that don't set their own.
+type QueueSettings struct {
+	Name        string         `json:"name"`
+	RetryPolicy *RetryPolicy   `json:"retry_policy,omitempty"`
+	Backoff     *BackoffPolicy `json:"backoff,omitempty"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// Attempt is one execution of a job by a worker.
+type Attempt struct {
+	ID        int64      `json:"id"`
+	JobID     string     `json:"job_id"`
+	Number    int        `json:"number"`
+	Worker    string     `json:"worker"`   // host:pid/goroutine that ran it
+	LeaseID   string     `json:"lease_id"` // jobs.worker_id while it ran
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	Usage
+}
+
+// Usage is how an attempt's process ended and what it consumed. A SIGKILL
+// with no timeout or cancel error usually means the kernel OOM killer.
+type Usage struct {
+	ExitCode *int   `json:"exit_code,omitempty"` // -1 when killed by a signal
+	Signal   string `json:"signal,omitempty"`    // e.g. SIGKILL
+	WallMS   int64  `json:"wall_ms"`
+	UserMS   int64  `json:"user_cpu_ms"`
+	SysMS    int64  `json:"sys_cpu_ms"`
+	MaxRSSKB int64  `json:"max_rss_kb"`
 }
 
 type Config struct {
-    MaxRetries  int `json:"max_retries"`
-    BackoffBase int `json:"backoff_base"`
-}
\ No newline at end of file
+    MaxRetries  int           `json:"max_retries"`
+    BackoffBase float64       `json:"backoff_base"`
+    BackoffStrategy string    `json:"backoff_strategy"`
+    BackoffMin  time.Duration `json:"backoff_min"`
+    BackoffMax  time.Duration `json:"backoff_max"`
+    JobTimeout  time.Duration `json:"job_timeout"`
+    MaxLogBytes int64         `json:"max_log_bytes"`
+    MaxResultBytes int64      `json:"max_result_bytes"`
+}
+type CatchUpPolicy string
+
+const (
+    CatchUpSkip    CatchUpPolicy = "skip"     // drop ticks missed while no scheduler was running
+    CatchUpRunOnce CatchUpPolicy = "run-once" // run a single job for all missed ticks
+    CatchUpRunAll  CatchUpPolicy = "run-all"  // run one job per missed tick
+)
+
+type Schedule struct {
+	ID         string        `json:"id"`
+	Spec       string        `json:"spec"`
+	Command    string        `json:"command"`
+	Queue      string        `json:"queue"`
+	MaxRetries int           `json:"max_retries"`
+	CatchUp    CatchUpPolicy `json:"catch_up"`
+	Paused     bool          `json:"paused"`
+	NextRunAt  time.Time     `json:"next_run_at"`
+	LastRunAt  *time.Time    `json:"last_run_at,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
@@ -1,7 +1,7 @@
 #!/usr/bin/env bash
 set -euo pipefail
-DB=./test_conc.db
-rm -f "$DB"
+DB=${QUEUECTL_TEST_DB:-./test_conc.db}
+case "$DB" in postgres://*|postgresql://*) ;; *) rm -f "$DB" "$DB-wal" "$DB-shm" ;; esac
 
 # start two workers
 go run . worker start --count 1 --poll 200ms --db "$DB" > w1.log 2>&1 &
@@ -19,7 +19,7 @@ done
 sleep 4
 
 # verify no duplicates: check completed count equals 20
-sqlite3 "$DB" "SELECT state, COUNT(*) FROM jobs GROUP BY state;"
+go run . status --db "$DB"
 
 kill "$W1" "$W2" || true
 wait "$W1" "$W2" 2>/dev/null || true
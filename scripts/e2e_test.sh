@@ -1,8 +1,9 @@
 #!/usr/bin/env bash
 set -euo pipefail
 
-DB=./test_e2e.db
-rm -f "$DB"
+# Set QUEUECTL_TEST_DB=postgres://... to run against a (scratch) Postgres database.
+DB=${QUEUECTL_TEST_DB:-./test_e2e.db}
+case "$DB" in postgres://*|postgresql://*) ;; *) rm -f "$DB" "$DB-wal" "$DB-shm" ;; esac
 echo "Using DB: $DB"
 
 # 1. start worker in background
@@ -20,7 +21,7 @@ go run . enqueue "bash -c 'exit 1'" --db "$DB"
 sleep 6
 
 # 4. inspect DB summary
-sqlite3 "$DB" "SELECT state, COUNT(*) FROM jobs GROUP BY state;"
+go run . status --db "$DB"
 
 # 5. show worker logs and kill
 kill "$WPID"
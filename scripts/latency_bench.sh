@@ -0,0 +1,36 @@
+#!/usr/bin/env bash
+# Measures enqueue-to-start latency: N jobs are enqueued one at a time from
+# separate processes, spaced out so the workers are idle (and their polling
+# backed off) when each one arrives. Extra arguments go to `worker start`,
+# e.g. `--max-poll 10s`. Needs jq.
+set -euo pipefail
+N=${N:-20}
+DB=${QUEUECTL_TEST_DB:-./test_latency.db}
+case "$DB" in postgres://*|postgresql://*) ;; *) rm -f "$DB" "$DB-wal" "$DB-shm" ;; esac
+
+WORK=$(mktemp -d)
+trap 'rm -rf "$WORK"' EXIT
+go build -o "$WORK/queuectl" .
+Q="$WORK/queuectl"
+
+"$Q" worker start --count 4 --db "$DB" "$@" > "$WORK/worker.log" 2>&1 &
+WPID=$!
+sleep 3 # let the idle poll interval back off to its maximum
+
+ids=()
+for _ in $(seq 1 "$N"); do
+  ids+=("$("$Q" enqueue "true" --db "$DB" | sed -n 's/^Enqueued: \([^ ]*\).*/\1/p')")
+  sleep 0.$((RANDOM % 5 + 5))
+done
+sleep 1
+kill "$WPID"; wait "$WPID" 2>/dev/null || true
+
+# created_at and started_at are RFC 3339 with nanoseconds; jq's date parsing
+# drops fractions, so add them back.
+for id in "${ids[@]}"; do
+  "$Q" inspect "$id" --json --db "$DB"
+done | jq -rs '
+  def secs: (.[0:19] + "Z" | fromdateiso8601) + ((capture("\\.(?<f>[0-9]+)").f // "0") | "0." + . | tonumber);
+  [ .[] | select(.attempts | length > 0) | ((.attempts[0].started_at | secs) - (.job.created_at | secs)) * 1000 ]
+  | sort
+  | "jobs=\(length) latency_ms min=\(.[0] | floor) p50=\(.[length / 2 | floor] | floor) p95=\(.[length * 0.95 | floor] | floor) max=\(.[-1] | floor)"'
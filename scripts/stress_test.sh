@@ -0,0 +1,55 @@
+#!/usr/bin/env bash
+# Runs JOBS jobs through PROCS worker processes with COUNT goroutines each
+# (50 workers by default) and fails on any duplicate execution or
+# "database is locked" error.
+set -euo pipefail
+PROCS=${PROCS:-10}
+COUNT=${COUNT:-5}
+JOBS=${JOBS:-500}
+DB=${QUEUECTL_TEST_DB:-./test_stress.db}
+case "$DB" in postgres://*|postgresql://*) ;; *) rm -f "$DB" "$DB-wal" "$DB-shm" ;; esac
+
+WORK=$(mktemp -d)
+OUT="$WORK/executions"
+touch "$OUT"
+go build -o "$WORK/queuectl" .
+Q="$WORK/queuectl"
+
+# Enqueue half up front and the rest while the workers run, so enqueues
+# contend with acquisition too.
+enqueue() {
+  for i in $(seq "$1" "$2"); do
+    "$Q" enqueue "echo $i >> $OUT" --db "$DB" > /dev/null
+  done
+}
+enqueue 1 $((JOBS / 2))
+
+PIDS=()
+for p in $(seq 1 "$PROCS"); do
+  "$Q" worker start --count "$COUNT" --poll 50ms --db "$DB" > "$WORK/w$p.log" 2>&1 &
+  PIDS+=($!)
+done
+enqueue $((JOBS / 2 + 1)) "$JOBS"
+
+for _ in $(seq 1 120); do
+  done_n=$("$Q" status --db "$DB" | awk '/^total/ { for (i = 1; i <= NF; i++) if ($i ~ /^completed=/) { sub("completed=", "", $i); print $i } }')
+  [ "$done_n" -ge "$JOBS" ] && break
+  sleep 1
+done
+
+kill "${PIDS[@]}" 2>/dev/null || true
+wait "${PIDS[@]}" 2>/dev/null || true
+
+"$Q" status --db "$DB"
+runs=$(wc -l < "$OUT")
+unique=$(sort -u "$OUT" | wc -l)
+locked=$(cat "$WORK"/w*.log | grep -ci -e 'database is locked' -e 'SQLITE_BUSY' || true)
+errors=$(cat "$WORK"/w*.log | grep -c -e 'acquire error' -e 'update .* error' -e 'record attempt error' -e 'log error' || true)
+echo "workers=$((PROCS * COUNT)) jobs=$JOBS executions=$runs unique=$unique duplicates=$((runs - unique)) lock_errors=$locked store_errors=$errors"
+
+status=0
+[ "$unique" -eq "$JOBS" ] || { echo "FAIL: $((JOBS - unique)) job(s) never ran"; status=1; }
+[ "$runs" -eq "$unique" ] || { echo "FAIL: duplicate executions"; status=1; }
+[ "$locked" -eq 0 ] && [ "$errors" -eq 0 ] || { echo "FAIL: store errors, see $WORK/w*.log"; status=1; }
+[ "$status" -eq 0 ] && rm -rf "$WORK" && echo PASS
+exit "$status"
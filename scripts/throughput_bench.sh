@@ -0,0 +1,47 @@
+#!/usr/bin/env bash
+# Measures how fast one worker process drains JOBS trivial jobs, leasing one
+# job per transaction (--batch 1) and then in batches (the default). With
+# BASELINE=<git ref>, that revision's binary is measured as well. Seeds the
+# queue with the sqlite3 CLI, so SQLite only.
+set -euo pipefail
+JOBS=${JOBS:-10000}
+COUNT=${COUNT:-16}
+
+WORK=$(mktemp -d)
+trap 'rm -rf "$WORK"' EXIT
+go build -o "$WORK/queuectl" .
+
+# run <label> <binary> [worker flags...]
+run() {
+  local label=$1 bin=$2; shift 2
+  local db="$WORK/$label.db"
+  "$bin" status --db "$db" > /dev/null # creates the schema
+  # Only columns the baseline schema has too; the rest take their defaults.
+  sqlite3 -cmd ".timeout 5000" "$db" "
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < $JOBS)
+INSERT INTO jobs (id, command, state, error, created_at, updated_at)
+SELECT 'bench-' || i, 'true', 'pending', '', datetime('now'), datetime('now') FROM n;"
+
+  local start end done_n=0
+  start=$(date +%s.%N)
+  "$bin" worker start --count "$COUNT" --db "$db" "$@" > "$WORK/$label.log" 2>&1 &
+  local pid=$!
+  while [ "$done_n" -lt "$JOBS" ]; do
+    sleep 0.2
+    kill -0 "$pid" 2>/dev/null || { echo "FAIL: $label worker exited"; cat "$WORK/$label.log"; exit 1; }
+    done_n=$(sqlite3 -cmd ".timeout 5000" "$db" "SELECT COUNT(*) FROM jobs WHERE state = 'completed'")
+  done
+  end=$(date +%s.%N)
+  kill "$pid"; wait "$pid" 2>/dev/null || true
+  awk -v l="$label" -v n="$JOBS" -v s="$start" -v e="$end" \
+    'BEGIN { printf "%-12s %d jobs in %.1fs = %.0f jobs/s\n", l, n, e - s, n / (e - s) }'
+}
+
+if [ -n "${BASELINE:-}" ]; then
+  git worktree add -q "$WORK/baseline" "$BASELINE"
+  (cd "$WORK/baseline" && go build -o "$WORK/queuectl-baseline" .)
+  git worktree remove --force "$WORK/baseline"
+  run baseline "$WORK/queuectl-baseline"
+fi
+run batch-1 "$WORK/queuectl" --batch 1
+run batched "$WORK/queuectl"